@@ -1,4 +1,4 @@
-package cluster
+package dctr
 
 import (
 	"context"
@@ -6,8 +6,6 @@ import (
 	"regexp"
 
 	"github.com/moby/moby/client"
-
-	"github.com/tilt-dev/ctlptl/internal/dctr"
 )
 
 const (
@@ -30,7 +28,7 @@ func isShortID(id string) bool {
 }
 
 type detectInContainer interface {
-	insideContainer(ctx context.Context) string
+	InsideContainer(ctx context.Context) string
 }
 
 // InsideContainer checks the current host and docker client to see if we are
@@ -42,10 +40,10 @@ type detectInContainer interface {
 //     container
 //
 // Returns a non-empty string representing the container ID if inside a container.
-func insideContainer(ctx context.Context, dockerClient dctr.Client) string {
+func InsideContainer(ctx context.Context, dockerClient Client) string {
 	// allows fake client to mock the result
 	if detect, ok := dockerClient.(detectInContainer); ok {
-		return detect.insideContainer(ctx)
+		return detect.InsideContainer(ctx)
 	}
 
 	if dockerClient.DaemonHost() != "unix:///var/run/docker.sock" {
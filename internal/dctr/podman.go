@@ -0,0 +1,104 @@
+package dctr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/docker/cli/cli/config"
+
+	"github.com/tilt-dev/ctlptl/pkg/docker"
+)
+
+// The socket that Docker Engine listens on by default.
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// The name of the network that containers join by default.
+//
+// Docker calls it "bridge". Podman calls it "podman".
+func DefaultNetwork(c Client) string {
+	if docker.IsPodmanHost(c.DaemonHost()) {
+		return "podman"
+	}
+	return "bridge"
+}
+
+// The CLI that can exec into containers managed by this client.
+//
+// Podman containers are only visible to the docker CLI if it's pointed at the
+// Podman socket, so prefer the podman CLI when it's installed.
+//
+// When we found the Podman socket on our own, the docker CLI won't
+// know about it, so the podman CLI is the only one that can work.
+func Binary(c Client) string {
+	if !docker.IsPodmanHost(c.DaemonHost()) {
+		return "docker"
+	}
+	if podmanHostFromEnv() != "" {
+		return "podman"
+	}
+	_, err := exec.LookPath("podman")
+	if err == nil {
+		return "podman"
+	}
+	return "docker"
+}
+
+// Decides whether to talk to Podman's Docker-compatible socket
+// instead of the docker CLI's default host.
+//
+// Returns the empty string to use the docker CLI's defaults.
+func podmanHostFromEnv() string {
+	// Respect any explicit Docker configuration.
+	if os.Getenv("DOCKER_HOST") != "" || os.Getenv("DOCKER_CONTEXT") != "" {
+		return ""
+	}
+	if ctx := config.LoadDefaultConfigFile(io.Discard).CurrentContext; ctx != "" && ctx != "default" {
+		return ""
+	}
+
+	// Podman's equivalent of DOCKER_HOST.
+	if host := os.Getenv("CONTAINER_HOST"); host != "" && docker.IsPodmanHost(host) {
+		return host
+	}
+
+	socket := podmanSocket()
+	if socket == "" {
+		return ""
+	}
+
+	// If kind is going to create its nodes with Podman, the registry
+	// needs to live in Podman too, so that they can share a network.
+	if os.Getenv("KIND_EXPERIMENTAL_PROVIDER") == "podman" {
+		return "unix://" + socket
+	}
+
+	// Otherwise, only fall back to Podman if Docker isn't around.
+	if _, err := os.Stat(defaultDockerSocket); err == nil {
+		return ""
+	}
+	return "unix://" + socket
+}
+
+// Finds the Podman API socket, rootless first.
+//
+// The socket only exists when the Podman API service is running, e.g.,
+// `systemctl --user enable --now podman.socket`.
+func podmanSocket() string {
+	candidates := []string{}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"))
+	} else {
+		candidates = append(candidates, fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid()))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package dctr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHostClient struct {
+	Client
+	host string
+}
+
+func (c fakeHostClient) DaemonHost() string { return c.host }
+
+// Sets up an environment with a Podman socket and no podman CLI on the PATH.
+func setupPodmanEnv(t *testing.T) string {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "podman", "podman.sock")
+	require.NoError(t, os.MkdirAll(filepath.Dir(socket), 0755))
+	require.NoError(t, os.WriteFile(socket, nil, 0644))
+
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("CONTAINER_HOST", "")
+	t.Setenv("KIND_EXPERIMENTAL_PROVIDER", "podman")
+	t.Setenv("PATH", t.TempDir())
+	return "unix://" + socket
+}
+
+func TestBinaryDocker(t *testing.T) {
+	setupPodmanEnv(t)
+	assert.Equal(t, "docker", Binary(fakeHostClient{host: "unix:///var/run/docker.sock"}))
+}
+
+func TestBinaryPodmanAutoDetected(t *testing.T) {
+	host := setupPodmanEnv(t)
+
+	// The docker CLI can't see a socket we found on our own,
+	// so use podman even if we can't find it.
+	assert.Equal(t, "podman", Binary(fakeHostClient{host: host}))
+}
+
+func TestBinaryPodmanDockerHost(t *testing.T) {
+	host := setupPodmanEnv(t)
+	t.Setenv("DOCKER_HOST", host)
+
+	// The docker CLI follows DOCKER_HOST to the Podman socket.
+	assert.Equal(t, "docker", Binary(fakeHostClient{host: host}))
+}
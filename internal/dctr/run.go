@@ -20,17 +20,22 @@ import (
 type Client interface {
 	DaemonHost() string
 	ImagePull(ctx context.Context, image string, options client.ImagePullOptions) (client.ImagePullResponse, error)
+	ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error)
 
 	ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error)
 	ContainerInspect(ctx context.Context, containerID string, options client.ContainerInspectOptions) (client.ContainerInspectResult, error)
 	ContainerRemove(ctx context.Context, id string, options client.ContainerRemoveOptions) (client.ContainerRemoveResult, error)
 	ContainerCreate(ctx context.Context, options client.ContainerCreateOptions) (client.ContainerCreateResult, error)
 	ContainerStart(ctx context.Context, containerID string, options client.ContainerStartOptions) (client.ContainerStartResult, error)
+	ContainerStop(ctx context.Context, containerID string, options client.ContainerStopOptions) (client.ContainerStopResult, error)
+	ContainerCommit(ctx context.Context, containerID string, options client.ContainerCommitOptions) (client.ContainerCommitResult, error)
 
 	ServerVersion(ctx context.Context, options client.ServerVersionOptions) (client.ServerVersionResult, error)
 	Info(ctx context.Context, options client.InfoOptions) (client.SystemInfoResult, error)
 	NetworkConnect(ctx context.Context, networkID string, options client.NetworkConnectOptions) (client.NetworkConnectResult, error)
 	NetworkDisconnect(ctx context.Context, networkID string, options client.NetworkDisconnectOptions) (client.NetworkDisconnectResult, error)
+
+	VolumeRemove(ctx context.Context, volumeID string, options client.VolumeRemoveOptions) (client.VolumeRemoveResult, error)
 }
 
 type CLI interface {
@@ -62,6 +67,9 @@ func NewCLI(streams genericclioptions.IOStreams) (CLI, error) {
 	flagSet := pflag.NewFlagSet("docker", pflag.ContinueOnError)
 	opts.InstallFlags(flagSet)
 	opts.SetDefaultOptions(flagSet)
+	if host := podmanHostFromEnv(); host != "" {
+		opts.Hosts = []string{host}
+	}
 	err = dockerCli.Initialize(opts)
 	if err != nil {
 		return nil, fmt.Errorf("initializing docker client: %v", err)
@@ -129,7 +137,7 @@ func Run(ctx context.Context, cli CLI, name string, config *container.Config, ho
 			return fmt.Errorf("creating %s: %v", name, err)
 		}
 
-		err := pull(ctx, cli, config.Image)
+		err := Pull(ctx, cli, config.Image)
 		if err != nil {
 			return fmt.Errorf("pulling image %s: %v", config.Image, err)
 		}
@@ -153,7 +161,20 @@ func Run(ctx context.Context, cli CLI, name string, config *container.Config, ho
 	return nil
 }
 
-func pull(ctx context.Context, cli CLI, img string) error {
+// Checks whether an image is on the Docker server.
+func ImageExists(ctx context.Context, c Client, img string) (bool, error) {
+	filters := client.Filters{}
+	filters.Add("reference", img)
+	list, err := c.ImageList(ctx, client.ImageListOptions{Filters: filters})
+	if err != nil {
+		return false, fmt.Errorf("listing image %s: %v", img, err)
+	}
+	return len(list.Items) > 0, nil
+}
+
+// Pulls an image onto the Docker server, authenticating with the
+// credentials in the Docker CLI config.
+func Pull(ctx context.Context, cli CLI, img string) error {
 	c := cli.Client()
 
 	ref, err := reference.ParseNormalizedNamed(img)
@@ -3,6 +3,7 @@ package exec
 import (
 	"context"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -18,15 +19,33 @@ type CmdRunner interface {
 
 type RealCmdRunner struct{}
 
+// Creates a command that gets interrupted when the context is cancelled
+// (e.g., when the user hits Ctrl-C), so that tools like `kind create` can
+// clean up after themselves, rather than being killed outright.
+//
+// If a tool ignores the interrupt, a second Ctrl-C kills everything.
+func commandContext(ctx context.Context, cmd string, args ...string) *exec.Cmd {
+	c := exec.CommandContext(ctx, cmd, args...)
+	c.Cancel = func() error {
+		err := c.Process.Signal(os.Interrupt)
+		if err != nil {
+			// Windows can't send interrupts to other processes.
+			return c.Process.Kill()
+		}
+		return nil
+	}
+	return c
+}
+
 func (RealCmdRunner) Run(ctx context.Context, cmd string, args ...string) error {
 	// For some reason, ExitError only gets populated with Stderr if we call Output().
-	_, err := exec.CommandContext(ctx, cmd, args...).Output()
+	_, err := commandContext(ctx, cmd, args...).Output()
 
 	return err
 }
 
 func (RealCmdRunner) RunIO(ctx context.Context, iostreams genericclioptions.IOStreams, cmd string, args ...string) error {
-	c := exec.CommandContext(ctx, cmd, args...)
+	c := commandContext(ctx, cmd, args...)
 	c.Stdin = iostreams.In
 	c.Stderr = iostreams.ErrOut
 	c.Stdout = iostreams.Out
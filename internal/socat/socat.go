@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
 	"github.com/shirou/gopsutil/v3/process"
 
 	"github.com/tilt-dev/ctlptl/internal/dctr"
@@ -56,6 +58,56 @@ func (c *Controller) StartRemotePortforwarder(ctx context.Context) error {
 		&network.NetworkingConfig{})
 }
 
+// Returns the names of port-forwarding containers on the Docker server
+// that aren't running.
+func (c *Controller) StoppedRemotePortforwarders(ctx context.Context) ([]string, error) {
+	filters := client.Filters{}
+	filters.Add("name", serviceName)
+	list, err := c.cli.Client().ContainerList(ctx, client.ContainerListOptions{
+		All:     true,
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, co := range list.Items {
+		if co.State == container.StateRunning {
+			continue
+		}
+		for _, name := range co.Names {
+			if strings.TrimPrefix(name, "/") == serviceName {
+				names = append(names, serviceName)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// Returns the ports that local socat forwarders are listening on.
+func (c *Controller) LocalPortforwarderPorts() ([]int, error) {
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	ports := []int{}
+	for _, p := range processes {
+		cmdline, err := p.Cmdline()
+		if err != nil {
+			continue
+		}
+		var port int
+		_, err = fmt.Sscanf(cmdline, "socat TCP-LISTEN:%d,", &port)
+		if err == nil && strings.Contains(cmdline, serviceName) {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
 // Returns the socat process listening on a port, plus its commandline.
 func (c *Controller) socatProcessOnPort(port int) (*process.Process, string, error) {
 	processes, err := process.Processes()
@@ -74,12 +126,28 @@ func (c *Controller) socatProcessOnPort(port int) (*process.Process, string, err
 	return nil, "", nil
 }
 
+// Kills the local socat forwarder listening on a port, if there is one.
+func (c *Controller) StopLocalPortforwarder(ctx context.Context, port int) error {
+	existing, _, err := c.socatProcessOnPort(port)
+	if err != nil {
+		return fmt.Errorf("stop portforwarder: %v", err)
+	}
+	if existing == nil {
+		return nil
+	}
+	err = existing.KillWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("stop portforwarder: %v", err)
+	}
+	return nil
+}
+
 // Create a port-forwarding server on the local machine, forwarding connections
 // to the same port on the remote Docker server.
 func (c *Controller) StartLocalPortforwarder(ctx context.Context, port int) error {
 	args := []string{
 		fmt.Sprintf("TCP-LISTEN:%d,reuseaddr,fork", port),
-		fmt.Sprintf("EXEC:'docker exec -i %s socat STDIO TCP:localhost:%d'", serviceName, port),
+		fmt.Sprintf("EXEC:'%s exec -i %s socat STDIO TCP:localhost:%d'", dctr.Binary(c.cli.Client()), serviceName, port),
 	}
 
 	existing, cmdline, err := c.socatProcessOnPort(port)
@@ -16,5 +16,12 @@ func (r *Registry) GetObjectMeta() metav1.Object {
 	}
 }
 
+func (s *Snapshot) GetObjectMeta() metav1.Object {
+	return &metav1.ObjectMeta{
+		Name: s.Name,
+	}
+}
+
 var _ metav1.ObjectMetaAccessor = &Cluster{}
 var _ metav1.ObjectMetaAccessor = &Registry{}
+var _ metav1.ObjectMetaAccessor = &Snapshot{}
@@ -33,6 +33,28 @@ import (
 func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(ClusterWait)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.KindV1Alpha4Cluster != nil {
 		in, out := &in.KindV1Alpha4Cluster, &out.KindV1Alpha4Cluster
 		*out = new(v1alpha4.Cluster)
@@ -70,6 +92,37 @@ func (in *Cluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWait) DeepCopyInto(out *ClusterWait) {
+	*out = *in
+	if in.NodesReady != nil {
+		in, out := &in.NodesReady, &out.NodesReady
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CoreDNS != nil {
+		in, out := &in.CoreDNS, &out.CoreDNS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DefaultServiceAccount != nil {
+		in, out := &in.DefaultServiceAccount, &out.DefaultServiceAccount
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWait.
+func (in *ClusterWait) DeepCopy() *ClusterWait {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWait)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterList) DeepCopyInto(out *ClusterList) {
 	*out = *in
@@ -111,6 +164,10 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		*out = new(localregistrygo.LocalRegistryHostingV1)
 		**out = **in
 	}
+	if in.ExpirationTimestamp != nil {
+		in, out := &in.ExpirationTimestamp, &out.ExpirationTimestamp
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -192,6 +249,11 @@ func (in *Registry) DeepCopyInto(out *Registry) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(RegistryStorage)
+		**out = **in
+	}
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -214,6 +276,65 @@ func (in *Registry) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryImage) DeepCopyInto(out *RegistryImage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	in.PushTimestamp.DeepCopyInto(&out.PushTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryImage.
+func (in *RegistryImage) DeepCopy() *RegistryImage {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryImageList) DeepCopyInto(out *RegistryImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RegistryImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryImageList.
+func (in *RegistryImageList) DeepCopy() *RegistryImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RegistryImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryList) DeepCopyInto(out *RegistryList) {
 	*out = *in
@@ -250,6 +371,11 @@ func (in *RegistryList) DeepCopyObject() runtime.Object {
 func (in *RegistryStatus) DeepCopyInto(out *RegistryStatus) {
 	*out = *in
 	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	if in.ListenAddresses != nil {
+		in, out := &in.ListenAddresses, &out.ListenAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Networks != nil {
 		in, out := &in.Networks, &out.Networks
 		*out = make([]string, len(*in))
@@ -267,6 +393,11 @@ func (in *RegistryStatus) DeepCopyInto(out *RegistryStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(RegistryStorage)
+		**out = **in
+	}
 	if in.Warnings != nil {
 		in, out := &in.Warnings, &out.Warnings
 		*out = make([]string, len(*in))
@@ -285,6 +416,106 @@ func (in *RegistryStatus) DeepCopy() *RegistryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryStorage) DeepCopyInto(out *RegistryStorage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryStorage.
+func (in *RegistryStorage) DeepCopy() *RegistryStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Snapshot) DeepCopyInto(out *Snapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(Cluster)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]SnapshotNode, len(*in))
+		copy(*out, *in)
+	}
+	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Snapshot.
+func (in *Snapshot) DeepCopy() *Snapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(Snapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Snapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotList) DeepCopyInto(out *SnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Snapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotList.
+func (in *SnapshotList) DeepCopy() *SnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotNode) DeepCopyInto(out *SnapshotNode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotNode.
+func (in *SnapshotNode) DeepCopy() *SnapshotNode {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TypeMeta) DeepCopyInto(out *TypeMeta) {
 	*out = *in
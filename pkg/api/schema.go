@@ -44,3 +44,43 @@ func (obj *RegistryList) GroupVersionKind() schema.GroupVersionKind {
 }
 
 var _ runtime.Object = &RegistryList{}
+
+func (obj *RegistryImage) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *RegistryImage) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *RegistryImage) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &RegistryImage{}
+
+func (obj *RegistryImageList) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *RegistryImageList) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *RegistryImageList) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &RegistryImageList{}
+
+func (obj *Snapshot) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *Snapshot) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *Snapshot) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &Snapshot{}
+
+func (obj *SnapshotList) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *SnapshotList) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *SnapshotList) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &SnapshotList{}
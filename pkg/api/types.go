@@ -39,6 +39,21 @@ type Cluster struct {
 	// The name of the tool used to create this cluster.
 	Product string `json:"product,omitempty" yaml:"product,omitempty"`
 
+	// User-defined labels, for selecting clusters with `ctlptl get -l`.
+	//
+	// Stored on the cluster itself, in the kube-public/ctlptl-cluster-spec
+	// configmap. Labels can be changed or removed without re-creating the cluster.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// How long the cluster should live, as a Go duration (e.g., 8h).
+	//
+	// Once the TTL has passed since ctlptl created the cluster,
+	// `ctlptl gc` will delete it. Clusters without a TTL never expire,
+	// unless `ctlptl gc` is run with --max-age.
+	//
+	// Stored with the labels, so can be changed or removed without re-creating the cluster.
+	TTL string `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
 	// Make sure that the cluster has access to at least this many
 	// CPUs. This is mostly helpful for ensuring that your Docker Desktop
 	// VM has enough CPU. If ctlptl can't guarantee this many
@@ -59,6 +74,31 @@ type Cluster struct {
 	// Not supported on all cluster products.
 	RegistryAuths []RegistryAuth `json:"registryAuths,omitempty" yaml:"registryAuths,omitempty"`
 
+	// Images to copy from the host's Docker daemon into the cluster
+	// after it's created, so that pods don't have to pull them.
+	// Images added to an existing cluster are loaded on the next apply.
+	//
+	// Images that aren't on the host are pulled first.
+	//
+	// Supported on kind, k3d, and minikube.
+	Images []string `json:"images,omitempty" yaml:"images,omitempty"`
+
+	// Manifests to apply to the cluster once it's healthy, like addons
+	// and namespaces. Each entry is a file, a directory of YAML files,
+	// or an http(s) URL. Relative paths are relative to the working directory.
+	//
+	// Manifests are server-side applied on every `ctlptl apply`, so changes
+	// are picked up without re-creating the cluster. ctlptl waits for
+	// any Deployments in the manifests to become available. Removing a
+	// manifest doesn't delete the objects that ctlptl already applied.
+	Manifests []string `json:"manifests,omitempty" yaml:"manifests,omitempty"`
+
+	// Readiness gates that ctlptl checks after creating the cluster,
+	// so that the cluster is ready to run workloads when `ctlptl apply` exits.
+	//
+	// All gates are on by default.
+	Wait *ClusterWait `json:"wait,omitempty" yaml:"wait,omitempty"`
+
 	// The desired version of Kubernetes to run.
 	//
 	// Examples:
@@ -104,6 +144,9 @@ type ClusterStatus struct {
 	// The number of CPU. Only applicable to local clusters.
 	CPUs int `json:"cpus,omitempty" yaml:"cpus,omitempty"`
 
+	// The number of nodes in the cluster.
+	Nodes int `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+
 	// Whether this is the current cluster in `kubectl`
 	Current bool `json:"current,omitempty" yaml:"current,omitempty"`
 
@@ -117,10 +160,36 @@ type ClusterStatus struct {
 	// v1.19.3-34+fa32ff1c160058
 	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
 
+	// When the cluster expires, according to its TTL.
+	//
+	// Nil if the cluster has no TTL.
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty" yaml:"expirationTimestamp,omitempty"`
+
 	// Populated when we encounter an error reading the cluster status.
 	Error string `json:"error,omitempty"`
 }
 
+// ClusterWait describes what ctlptl waits for before it considers
+// a new cluster ready.
+//
+// The apiserver health check always runs. Unset gates default to true.
+//
+// If the cluster has manifests, ctlptl waits for nodes and cluster DNS
+// after it applies them, because the manifests may install the CNI
+// (e.g., with kind's networking.disableDefaultCNI).
+type ClusterWait struct {
+	// Wait for every node to report Ready.
+	NodesReady *bool `json:"nodesReady,omitempty" yaml:"nodesReady,omitempty"`
+
+	// Wait for the cluster DNS deployment in kube-system
+	// (labeled k8s-app=kube-dns) to become available.
+	CoreDNS *bool `json:"coreDNS,omitempty" yaml:"coreDNS,omitempty"`
+
+	// Wait for the default ServiceAccount in the default namespace,
+	// which pods need before they can be created.
+	DefaultServiceAccount *bool `json:"defaultServiceAccount,omitempty" yaml:"defaultServiceAccount,omitempty"`
+}
+
 // MinikubeCluster describes minikube-specific options for starting a cluster.
 //
 // Options in this struct, when possible, should match the flags
@@ -192,13 +261,37 @@ type Registry struct {
 	// The registry name. Get/set from the Docker container name.
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 
-	// The host IPv4 address to bind the container to.
+	// The host address to bind the container to.
+	//
+	// May be an IPv4 or IPv6 literal (e.g., 127.0.0.1 or ::1).
+	// Defaults to 127.0.0.1.
 	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
 
+	// Publish the registry on both IPv4 and IPv6.
+	//
+	// If the listen address is a loopback address (or unset), binds to
+	// both 127.0.0.1 and ::1. If the listen address is a wildcard address,
+	// binds to both 0.0.0.0 and ::.
+	DualStack bool `json:"dualStack,omitempty" yaml:"dualStack,omitempty"`
+
 	// The desired host port. Set to 0 to choose a random port,
 	// or to preserve the existing port.
 	Port int `json:"port,omitempty" yaml:"port,omitempty"`
 
+	// The port that the registry listens on inside the container.
+	//
+	// Defaults to 5000. The distribution flavor is configured to listen
+	// on this port. Other flavors must be configured by their image.
+	ContainerPort int `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
+
+	// The registry implementation that the image runs. Determines the
+	// default image, the default environment, and how garbage collection works.
+	//
+	// One of: distribution (the CNCF Distribution registry, a.k.a. registry:2), zot.
+	//
+	// Defaults to distribution.
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+
 	// Labels that must be attached to the running registry.
 	//
 	// If you change the set of labels, the registry must be stopped and
@@ -218,15 +311,36 @@ type Registry struct {
 	// Can be used to provide an alternate image or use a different registry
 	// than Docker Hub.
 	//
-	// Defaults to `docker.io/library/registry:2`.
+	// Defaults to `docker.io/library/registry:2` for the distribution flavor,
+	// and `ghcr.io/project-zot/zot:latest` for the zot flavor.
 	Image string `json:"image,omitempty" yaml:"image,omitempty"`
 
+	// Persistent storage for the registry's images (optional).
+	//
+	// By default, images live in an anonymous volume that's thrown away
+	// whenever the registry container is re-created. Set a storage
+	// volume or host path to keep pushed images across re-creates.
+	Storage *RegistryStorage `json:"storage,omitempty" yaml:"storage,omitempty"`
+
 	// Most recently observed status of the registry.
 	// Populated by the system.
 	// Read-only.
 	Status RegistryStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
+// RegistryStorage describes where a registry keeps its images.
+//
+// At most one of the fields may be set.
+type RegistryStorage struct {
+	// The name of a Docker volume to mount at /var/lib/registry.
+	//
+	// Docker creates the volume if it doesn't exist.
+	Volume string `json:"volume,omitempty" yaml:"volume,omitempty"`
+
+	// A directory on the host machine to mount at /var/lib/registry.
+	HostPath string `json:"hostPath,omitempty" yaml:"hostPath,omitempty"`
+}
+
 type RegistryStatus struct {
 	// When the registry was first created.
 	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
@@ -234,18 +348,26 @@ type RegistryStatus struct {
 	// The IPv4 address for the bridge network.
 	IPAddress string `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
 
-	// The public IPv4 address that the registry is listening on on the host machine.
+	// The IPv6 address for the bridge network, if IPv6 is enabled.
+	IPv6Address string `json:"ipv6Address,omitempty" yaml:"ipv6Address,omitempty"`
+
+	// The public address that the registry is listening on on the host machine.
+	//
+	// If the registry listens on multiple addresses, prefers IPv4.
 	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
 
+	// All the public addresses that the registry is listening on on the host machine.
+	ListenAddresses []string `json:"listenAddresses,omitempty" yaml:"listenAddresses,omitempty"`
+
 	// The public port that the registry is listening on on the host machine.
 	HostPort int `json:"hostPort,omitempty" yaml:"hostPort,omitempty"`
 
 	// The private port that the registry is listening on inside the registry network.
-	//
-	// We try to make this not configurable, because there's no real reason not
-	// to use the default registry port 5000.
 	ContainerPort int `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
 
+	// The registry implementation running in the container.
+	Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+
 	// Networks that the registry container is connected to.
 	Networks []string `json:"networks,omitempty" yaml:"networks,omitempty"`
 
@@ -266,6 +388,18 @@ type RegistryStatus struct {
 	// Image for the running container.
 	Image string `json:"image,omitempty" yaml:"image,omitempty"`
 
+	// Persistent storage mounted into the running container.
+	//
+	// Empty if the registry stores images in an anonymous volume.
+	Storage *RegistryStorage `json:"storage,omitempty" yaml:"storage,omitempty"`
+
+	// Whether the registry answered the most recent HTTP probe of its API
+	// on the host port.
+	Ready bool `json:"ready,omitempty" yaml:"ready,omitempty"`
+
+	// The error from the most recent HTTP probe, if it failed.
+	LastProbeError string `json:"lastProbeError,omitempty" yaml:"lastProbeError,omitempty"`
+
 	// Warnings that occurred when reporting the registry status.
 	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
 }
@@ -279,3 +413,90 @@ type RegistryList struct {
 	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md
 	Items []Registry `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
+
+// RegistryImage describes a tagged image stored in a registry.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RegistryImage struct {
+	TypeMeta `yaml:",inline"`
+
+	// The name of the registry that stores the image.
+	Registry string `json:"registry,omitempty" yaml:"registry,omitempty"`
+
+	// The repository name, relative to the registry host.
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+
+	// The tag that points to the image.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// The digest of the manifest that the tag points to.
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// The total size of the image config and layers, in bytes.
+	//
+	// For multi-platform images, the size of the first platform.
+	Size int64 `json:"size,omitempty" yaml:"size,omitempty"`
+
+	// When the image was built, according to the image config.
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
+
+	// When the tag was last pushed to the registry.
+	PushTimestamp metav1.Time `json:"pushTimestamp,omitempty" yaml:"pushTimestamp,omitempty"`
+}
+
+// RegistryImageList is a list of RegistryImages.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RegistryImageList struct {
+	TypeMeta `json:",inline"`
+
+	// List of images.
+	Items []RegistryImage `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// Snapshot is a saved copy of a cluster's nodes, stored as local images,
+// that ctlptl can restore into a new cluster.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Snapshot struct {
+	TypeMeta `yaml:",inline"`
+
+	// The snapshot name.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// The name of the cluster that the snapshot was taken from.
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+
+	// The configuration of the cluster when the snapshot was taken,
+	// including its registry. Used to re-create the cluster on restore.
+	Spec *Cluster `json:"spec,omitempty" yaml:"spec,omitempty"`
+
+	// The images that store each node.
+	Nodes []SnapshotNode `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+
+	// When the snapshot was taken.
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
+}
+
+// SnapshotNode is the image that stores one node of a snapshot.
+type SnapshotNode struct {
+	// The name of the node container.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// The role of the node in the cluster (e.g., control-plane or worker).
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+
+	// The image that stores the node's filesystem.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// The size of the image, in bytes.
+	Size int64 `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// SnapshotList is a list of Snapshots.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SnapshotList struct {
+	TypeMeta `json:",inline"`
+
+	// List of snapshots.
+	Items []Snapshot `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -26,6 +26,19 @@ type Admin interface {
 	Delete(ctx context.Context, config *api.Cluster) error
 }
 
+// An extension of cluster admin that can stop a cluster without deleting it,
+// and start it again later.
+type AdminWithStop interface {
+	Stop(ctx context.Context, cluster *api.Cluster) error
+	Start(ctx context.Context, cluster *api.Cluster) error
+}
+
+// An extension of cluster admin that can copy images from the
+// host's Docker daemon into the nodes of a cluster.
+type AdminWithImageLoad interface {
+	LoadImages(ctx context.Context, cluster *api.Cluster, images []string) error
+}
+
 // An extension of cluster admin that indicates the cluster configuration can be
 // modified for use from inside containers.
 type AdminInContainer interface {
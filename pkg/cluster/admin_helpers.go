@@ -3,7 +3,11 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -13,16 +17,16 @@ import (
 )
 
 func applyContainerdPatchRegistryAPIV2(
-	ctx context.Context, runner exec.CmdRunner, iostreams genericclioptions.IOStreams,
+	ctx context.Context, runner exec.CmdRunner, iostreams genericclioptions.IOStreams, dockerBinary string,
 	nodes []string, desired *api.Cluster, registry *api.Registry) error {
 	for _, node := range nodes {
-		contents := fmt.Sprintf(`[host."http://%s:%d"]
-`, registry.Name, registry.Status.ContainerPort)
+		contents := fmt.Sprintf(`[host."http://%s"]
+`, net.JoinHostPort(registry.Name, strconv.Itoa(registry.Status.ContainerPort)))
 
 		localRegistryDir := fmt.Sprintf("/etc/containerd/certs.d/localhost:%d", registry.Status.HostPort)
 		err := runner.RunIO(ctx,
 			genericclioptions.IOStreams{In: strings.NewReader(contents), Out: iostreams.Out, ErrOut: iostreams.ErrOut},
-			"docker", "exec", "-i", node, "sh", "-c",
+			dockerBinary, "exec", "-i", node, "sh", "-c",
 			fmt.Sprintf("mkdir -p %s && cp /dev/stdin %s/hosts.toml", localRegistryDir, localRegistryDir))
 		if err != nil {
 			return errors.Wrap(err, "configuring registry")
@@ -31,7 +35,7 @@ func applyContainerdPatchRegistryAPIV2(
 		networkRegistryDir := fmt.Sprintf("/etc/containerd/certs.d/%s:%d", registry.Name, registry.Status.ContainerPort)
 		err = runner.RunIO(ctx,
 			genericclioptions.IOStreams{In: strings.NewReader(contents), Out: iostreams.Out, ErrOut: iostreams.ErrOut},
-			"docker", "exec", "-i", node, "sh", "-c",
+			dockerBinary, "exec", "-i", node, "sh", "-c",
 			fmt.Sprintf("mkdir -p %s && cp /dev/stdin %s/hosts.toml", networkRegistryDir, networkRegistryDir))
 		if err != nil {
 			return errors.Wrap(err, "configuring registry")
@@ -39,3 +43,20 @@ func applyContainerdPatchRegistryAPIV2(
 	}
 	return nil
 }
+
+// Serializes progress messages from parallel operations,
+// so that lines don't interleave.
+type progressWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newProgressWriter(out io.Writer) *progressWriter {
+	return &progressWriter{out: out}
+}
+
+func (w *progressWriter) Printf(format string, args ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = fmt.Fprintf(w.out, format, args...)
+}
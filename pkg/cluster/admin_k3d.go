@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
@@ -143,6 +144,57 @@ func (a *k3dAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	return nil
 }
 
+func (a *k3dAdmin) Stop(ctx context.Context, config *api.Cluster) error {
+	k3dName, err := k3dClusterName(config)
+	if err != nil {
+		return err
+	}
+	err = a.runner.RunIO(ctx, a.iostreams, "k3d", "cluster", "stop", k3dName)
+	if err != nil {
+		return errors.Wrap(err, "stopping k3d cluster")
+	}
+	return nil
+}
+
+func (a *k3dAdmin) Start(ctx context.Context, config *api.Cluster) error {
+	k3dName, err := k3dClusterName(config)
+	if err != nil {
+		return err
+	}
+	err = a.runner.RunIO(ctx, a.iostreams, "k3d", "cluster", "start", k3dName)
+	if err != nil {
+		return errors.Wrap(err, "starting k3d cluster")
+	}
+	return nil
+}
+
+// Copies images into every node of the cluster.
+//
+// k3d imports into each node in parallel on its own.
+func (a *k3dAdmin) LoadImages(ctx context.Context, config *api.Cluster, images []string) error {
+	k3dName, err := k3dClusterName(config)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, _ = fmt.Fprintf(a.iostreams.ErrOut, " 📦 Loading %d images into cluster %s\n", len(images), config.Name)
+	args := append([]string{"image", "import", "--cluster", k3dName}, images...)
+	err = a.runner.RunIO(ctx, a.iostreams, "k3d", args...)
+	if err != nil {
+		return errors.Wrap(err, "loading images into k3d cluster")
+	}
+	_, _ = fmt.Fprintf(a.iostreams.ErrOut, " 📦 Loaded images into cluster %s in %s\n", config.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func k3dClusterName(config *api.Cluster) (string, error) {
+	if !strings.HasPrefix(config.Name, "k3d-") {
+		return "", fmt.Errorf("all k3d clusters must have a name with the prefix k3d-*")
+	}
+	return strings.TrimPrefix(config.Name, "k3d-"), nil
+}
+
 func (a *k3dAdmin) version(ctx context.Context) (semver.Version, error) {
 	out := bytes.NewBuffer(nil)
 	err := a.runner.RunIO(ctx,
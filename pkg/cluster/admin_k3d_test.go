@@ -35,6 +35,27 @@ func TestK3DStartFlagsV4(t *testing.T) {
 	}, f.runner.LastArgs)
 }
 
+func TestK3DStopStart(t *testing.T) {
+	f := newK3DFixture()
+	ctx := context.Background()
+
+	err := f.a.Stop(ctx, &api.Cluster{Name: "k3d-my-cluster"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k3d", "cluster", "stop", "my-cluster"}, f.runner.LastArgs)
+
+	err = f.a.Start(ctx, &api.Cluster{Name: "k3d-my-cluster"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k3d", "cluster", "start", "my-cluster"}, f.runner.LastArgs)
+}
+
+func TestK3DLoadImages(t *testing.T) {
+	f := newK3DFixture()
+
+	err := f.a.LoadImages(context.Background(), &api.Cluster{Name: "k3d-my-cluster"}, []string{"postgres:16", "redis:7"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k3d", "image", "import", "--cluster", "my-cluster", "postgres:16", "redis:7"}, f.runner.LastArgs)
+}
+
 func TestK3DStartFlagsV5(t *testing.T) {
 	f := newK3DFixture()
 
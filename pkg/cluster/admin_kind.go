@@ -8,12 +8,15 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/containerd/errdefs"
 	"github.com/moby/moby/client"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/klog/v2"
@@ -24,6 +27,7 @@ import (
 	"github.com/tilt-dev/ctlptl/internal/dctr"
 	cexec "github.com/tilt-dev/ctlptl/internal/exec"
 	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/docker"
 )
 
 func kindNetworkName() string {
@@ -34,6 +38,11 @@ func kindNetworkName() string {
 	return networkName
 }
 
+// The container engine that kind creates nodes with.
+func kindProvider() string {
+	return os.Getenv("KIND_EXPERIMENTAL_PROVIDER")
+}
+
 // kindAdmin uses the kind CLI to manipulate a kind cluster,
 // once the underlying machine has been setup.
 type kindAdmin struct {
@@ -130,6 +139,15 @@ func (a *kindAdmin) Create(ctx context.Context, desired *api.Cluster, registry *
 
 	kindName := strings.TrimPrefix(clusterName, "kind-")
 
+	// The registry can only join the kind network if they're
+	// managed by the same container engine.
+	if registry != nil && kindProvider() == "podman" && !docker.IsPodmanHost(a.dockerClient.DaemonHost()) {
+		return fmt.Errorf("kind is using Podman (KIND_EXPERIMENTAL_PROVIDER=podman), "+
+			"but ctlptl is connected to Docker at %q. "+
+			"Set DOCKER_HOST to the Podman socket (e.g., unix://$XDG_RUNTIME_DIR/podman/podman.sock)",
+			a.dockerClient.DaemonHost())
+	}
+
 	// If a cluster has been registered with Kind, but deleted from our kubeconfig,
 	// Kind will refuse to create a new cluster. The only way to salvage it is
 	// to delete and recreate.
@@ -235,7 +253,7 @@ func (a *kindAdmin) applyContainerdPatchRegistryAPIV2(ctx context.Context, desir
 		filtered = append(filtered, node)
 	}
 
-	return applyContainerdPatchRegistryAPIV2(ctx, a.runner, a.iostreams,
+	return applyContainerdPatchRegistryAPIV2(ctx, a.runner, a.iostreams, dctr.Binary(a.dockerClient),
 		filtered, desired, registry)
 }
 
@@ -311,6 +329,100 @@ func (a *kindAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	return nil
 }
 
+// Stops the node containers. Kind has no stop command of its own.
+func (a *kindAdmin) Stop(ctx context.Context, config *api.Cluster) error {
+	nodes, err := a.nodeContainers(ctx, config)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		_, err := a.dockerClient.ContainerStop(ctx, node, client.ContainerStopOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "stopping kind node %s", node)
+		}
+	}
+	return nil
+}
+
+func (a *kindAdmin) Start(ctx context.Context, config *api.Cluster) error {
+	nodes, err := a.nodeContainers(ctx, config)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		_, err := a.dockerClient.ContainerStart(ctx, node, client.ContainerStartOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "starting kind node %s", node)
+		}
+	}
+	return nil
+}
+
+// Copies images into each node of the cluster in parallel.
+func (a *kindAdmin) LoadImages(ctx context.Context, config *api.Cluster, images []string) error {
+	nodes, err := a.nodeContainers(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	kindName := strings.TrimPrefix(config.Name, "kind-")
+	progress := newProgressWriter(a.iostreams.ErrOut)
+	g, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		// The load balancer isn't a Kubernetes node, so it never runs images.
+		if node == kindName+"-external-load-balancer" {
+			continue
+		}
+
+		g.Go(func() error {
+			start := time.Now()
+			progress.Printf(" 📦 Loading %d images into node %s\n", len(images), node)
+
+			out := bytes.NewBuffer(nil)
+			args := append([]string{"load", "docker-image", "--name", kindName, "--nodes", node}, images...)
+			err := a.runner.RunIO(ctx, genericclioptions.IOStreams{Out: out, ErrOut: out}, "kind", args...)
+			if err != nil {
+				return fmt.Errorf("loading images into node %s: %v\n%s", node, err, out.String())
+			}
+
+			progress.Printf(" 📦 Loaded images into node %s in %s\n", node, time.Since(start).Round(time.Millisecond))
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// The names of the containers running the cluster's nodes.
+func (a *kindAdmin) nodeContainers(ctx context.Context, config *api.Cluster) ([]string, error) {
+	clusterName := config.Name
+	if !strings.HasPrefix(clusterName, "kind-") {
+		return nil, fmt.Errorf("all kind clusters must have a name with the prefix kind-*")
+	}
+
+	kindName := strings.TrimPrefix(clusterName, "kind-")
+	filters := client.Filters{}
+	filters.Add("label", fmt.Sprintf("%s=%s", kindClusterLabel, kindName))
+	list, err := a.dockerClient.ContainerList(ctx, client.ContainerListOptions{
+		Filters: filters,
+		All:     true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing kind nodes")
+	}
+
+	names := []string{}
+	for _, c := range list.Items {
+		if len(c.Names) > 0 {
+			names = append(names, strings.TrimPrefix(c.Names[0], "/"))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no node containers found for kind cluster %s", kindName)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 func (a *kindAdmin) ModifyConfigInContainer(ctx context.Context, cluster *api.Cluster, containerID string, dockerClient dctr.Client, configWriter configWriter) error {
 	_, err := dockerClient.NetworkConnect(ctx, kindNetworkName(), client.NetworkConnectOptions{
 		Container: containerID,
@@ -369,6 +481,18 @@ func (a *kindAdmin) getKindVersion(ctx context.Context) (string, error) {
 	return parts[1], nil
 }
 
+func (a *kindAdmin) version(ctx context.Context) (semver.Version, error) {
+	v, err := a.getKindVersion(ctx)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	result, err := semver.ParseTolerant(v)
+	if err != nil {
+		return semver.Version{}, errors.Wrap(err, "parsing kind version")
+	}
+	return result, nil
+}
+
 // This table must be built up manually from the Kind release notes each
 // time a new Kind version is released :\
 var kindK8sNodeTable = map[string]map[string]string{
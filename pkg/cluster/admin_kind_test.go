@@ -3,9 +3,15 @@ package cluster
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/moby/moby/api/types/container"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/tilt-dev/ctlptl/internal/exec"
@@ -119,3 +125,120 @@ func TestKindClusterConfigWithPullThroughRegistries(t *testing.T) {
 	assert.Contains(t, kindConfig.ContainerdConfigPatches, expectedMirror)
 	assert.Contains(t, kindConfig.ContainerdConfigPatches, expectedAuth)
 }
+
+func TestPatchRegistryConfigPodman(t *testing.T) {
+	// Put a fake podman on the PATH.
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "podman"), []byte("#!/bin/sh\n"), 0755)
+	require.NoError(t, err)
+	t.Setenv("PATH", dir)
+
+	execs := [][]string{}
+	runner := exec.NewFakeCmdRunner(func(argv []string) string {
+		if argv[0] == "kind" && argv[1] == "get" && argv[2] == "nodes" {
+			return "kind-control-plane\n"
+		}
+		execs = append(execs, argv)
+		return ""
+	})
+	iostreams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	a := newKindAdmin(iostreams, runner, &fakeDockerClient{host: "unix:///run/user/1000/podman/podman.sock"})
+
+	err = a.applyContainerdPatchRegistryAPIV2(
+		context.Background(),
+		&api.Cluster{Name: "kind-kind"},
+		&api.Registry{Name: "test-registry"})
+	require.NoError(t, err)
+	require.Len(t, execs, 2)
+	assert.Equal(t, []string{"podman", "exec", "-i", "kind-control-plane"}, execs[0][:4])
+}
+
+func TestKindPodmanProviderMismatch(t *testing.T) {
+	t.Setenv("KIND_EXPERIMENTAL_PROVIDER", "podman")
+
+	runner := exec.NewFakeCmdRunner(func(argv []string) string {
+		return ""
+	})
+	iostreams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	a := newKindAdmin(iostreams, runner, &fakeDockerClient{host: "unix:///var/run/docker.sock"})
+
+	err := a.Create(context.Background(),
+		&api.Cluster{Name: "kind-kind"},
+		&api.Registry{Name: "kind-registry"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kind is using Podman")
+	assert.Nil(t, runner.LastArgs)
+}
+
+func TestKindStopStart(t *testing.T) {
+	runner := exec.NewFakeCmdRunner(func(argv []string) string {
+		return ""
+	})
+	iostreams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	dockerClient := &fakeDockerClient{
+		containers: []container.Summary{
+			{Names: []string{"/kind-worker"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"}},
+			{Names: []string{"/kind-control-plane"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"}},
+			{Names: []string{"/other-control-plane"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "other"}},
+		},
+	}
+	a := newKindAdmin(iostreams, runner, dockerClient)
+	ctx := context.Background()
+
+	err := a.Stop(ctx, &api.Cluster{Name: "kind-kind"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-control-plane", "kind-worker"}, dockerClient.stopped)
+
+	err = a.Start(ctx, &api.Cluster{Name: "kind-kind"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-control-plane", "kind-worker"}, dockerClient.restarted)
+
+	err = a.Stop(ctx, &api.Cluster{Name: "kind-missing"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "no node containers found for kind cluster missing", err.Error())
+	}
+}
+
+func TestKindLoadImages(t *testing.T) {
+	var mu sync.Mutex
+	commands := []string{}
+	runner := exec.NewFakeCmdRunner(func(argv []string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		commands = append(commands, strings.Join(argv, " "))
+		return ""
+	})
+	iostreams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	dockerClient := &fakeDockerClient{
+		containers: []container.Summary{
+			{Names: []string{"/kind-worker"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"}},
+			{Names: []string{"/kind-control-plane"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"}},
+			{Names: []string{"/kind-external-load-balancer"}, Labels: map[string]string{"io.x-k8s.kind.cluster": "kind"}},
+		},
+	}
+	a := newKindAdmin(iostreams, runner, dockerClient)
+
+	err := a.LoadImages(context.Background(), &api.Cluster{Name: "kind-kind"}, []string{"postgres:16", "redis:7"})
+	require.NoError(t, err)
+	sort.Strings(commands)
+	assert.Equal(t, []string{
+		"kind load docker-image --name kind --nodes kind-control-plane postgres:16 redis:7",
+		"kind load docker-image --name kind --nodes kind-worker postgres:16 redis:7",
+	}, commands)
+}
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/moby/moby/api/types/container"
@@ -269,7 +272,7 @@ func (a *minikubeAdmin) applyContainerdPatchRegistryAPIV2(ctx context.Context, d
 		return errors.Wrap(err, "configuring minikube registry")
 	}
 
-	return applyContainerdPatchRegistryAPIV2(ctx, a.runner, a.iostreams, nodes, desired, registry)
+	return applyContainerdPatchRegistryAPIV2(ctx, a.runner, a.iostreams, dctr.Binary(a.dockerClient), nodes, desired, registry)
 }
 
 // We still patch containerd so that the user can push/pull from localhost.
@@ -285,9 +288,14 @@ func (a *minikubeAdmin) applyContainerdPatchRegistryAPIV1(ctx context.Context, d
 
 	for _, node := range nodes {
 		networkHost := registry.Status.IPAddress
+		if networkHost == "" {
+			// The default network may be IPv6-only.
+			networkHost = registry.Status.IPv6Address
+		}
 		if networkMode.IsUserDefined() {
 			networkHost = registry.Name
 		}
+		networkAddr := net.JoinHostPort(networkHost, strconv.Itoa(registry.Status.ContainerPort))
 
 		// this is the most annoying sed expression i've ever had to write
 		// minikube does not give us great primitives for writing files on the host machine :\
@@ -299,8 +307,8 @@ func (a *minikubeAdmin) applyContainerdPatchRegistryAPIV1(ctx context.Context, d
 			fmt.Sprintf(
 				`s,\\\[plugins.\\\(\\\"\\\?.*cri\\\"\\\?\\\).registry.mirrors\\\],[plugins.\\\1.registry.mirrors]\\\n`+
 					`\ \ \ \ \ \ \ \ [plugins.\\\1.registry.mirrors.\\\"localhost:%d\\\"]\\\n`+
-					`\ \ \ \ \ \ \ \ \ \ endpoint\ =\ [\\\"http://%s:%d\\\"],`,
-				registry.Status.HostPort, networkHost, registry.Status.ContainerPort),
+					`\ \ \ \ \ \ \ \ \ \ endpoint\ =\ [\\\"http://%s\\\"],`,
+				registry.Status.HostPort, networkAddr),
 			configPath)
 		if err != nil {
 			return errors.Wrap(err, "configuring minikube registry")
@@ -331,18 +339,39 @@ func (a *minikubeAdmin) LocalRegistryHosting(ctx context.Context, desired *api.C
 	}
 	networkMode := container.Container.HostConfig.NetworkMode
 	networkHost := registry.Status.IPAddress
+	if networkHost == "" {
+		// The default network may be IPv6-only.
+		networkHost = registry.Status.IPv6Address
+	}
 	if networkMode.IsUserDefined() {
 		networkHost = registry.Name
 	}
+	networkAddr := net.JoinHostPort(networkHost, strconv.Itoa(registry.Status.ContainerPort))
 
 	return &localregistry.LocalRegistryHostingV1{
 		Host:                     fmt.Sprintf("localhost:%d", registry.Status.HostPort),
-		HostFromClusterNetwork:   fmt.Sprintf("%s:%d", networkHost, registry.Status.ContainerPort),
-		HostFromContainerRuntime: fmt.Sprintf("%s:%d", networkHost, registry.Status.ContainerPort),
+		HostFromClusterNetwork:   networkAddr,
+		HostFromContainerRuntime: networkAddr,
 		Help:                     "https://github.com/tilt-dev/ctlptl",
 	}, nil
 }
 
+func (a *minikubeAdmin) Stop(ctx context.Context, config *api.Cluster) error {
+	err := a.runner.RunIO(ctx, a.iostreams, "minikube", "stop", "-p", config.Name)
+	if err != nil {
+		return errors.Wrap(err, "stopping minikube cluster")
+	}
+	return nil
+}
+
+func (a *minikubeAdmin) Start(ctx context.Context, config *api.Cluster) error {
+	err := a.runner.RunIO(ctx, a.iostreams, "minikube", "start", "-p", config.Name)
+	if err != nil {
+		return errors.Wrap(err, "starting minikube cluster")
+	}
+	return nil
+}
+
 func (a *minikubeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	err := a.runner.RunIO(ctx, a.iostreams, "minikube", "delete", "-p", config.Name)
 	if err != nil {
@@ -350,3 +379,21 @@ func (a *minikubeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	}
 	return nil
 }
+
+// Copies images into every node of the cluster, one image at a time.
+//
+// minikube copies each image into all nodes on its own, and doesn't support
+// concurrent loads into the same profile.
+func (a *minikubeAdmin) LoadImages(ctx context.Context, config *api.Cluster, images []string) error {
+	for i, image := range images {
+		start := time.Now()
+		_, _ = fmt.Fprintf(a.iostreams.ErrOut, " 📦 Loading image %s into cluster %s (%d/%d)\n",
+			image, config.Name, i+1, len(images))
+		err := a.runner.RunIO(ctx, a.iostreams, "minikube", "image", "load", "-p", config.Name, image)
+		if err != nil {
+			return errors.Wrapf(err, "loading image %s into minikube cluster", image)
+		}
+		_, _ = fmt.Fprintf(a.iostreams.ErrOut, " 📦 Loaded image %s in %s\n", image, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
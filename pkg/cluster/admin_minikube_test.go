@@ -3,6 +3,7 @@ package cluster
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,23 @@ type minikubeFixture struct {
 	a      *minikubeAdmin
 }
 
+func TestMinikubeLoadImages(t *testing.T) {
+	f := newMinikubeFixture()
+	commands := []string{}
+	f.a.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		commands = append(commands, strings.Join(argv, " "))
+		return ""
+	})
+
+	// Loads run one at a time, in order.
+	err := f.a.LoadImages(context.Background(), &api.Cluster{Name: "minikube"}, []string{"redis:7", "postgres:16"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"minikube image load -p minikube redis:7",
+		"minikube image load -p minikube postgres:16",
+	}, commands)
+}
+
 func newMinikubeFixture() *minikubeFixture {
 	dockerClient := &fakeDockerClient{ncpu: 1}
 	iostreams := genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	osexec "os/exec"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,13 +22,15 @@ import (
 	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,7 +44,9 @@ import (
 	"github.com/tilt-dev/ctlptl/internal/exec"
 	"github.com/tilt-dev/ctlptl/internal/socat"
 	"github.com/tilt-dev/ctlptl/pkg/api"
+	ctlptlconfig "github.com/tilt-dev/ctlptl/pkg/config"
 	"github.com/tilt-dev/ctlptl/pkg/docker"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
 	"github.com/tilt-dev/ctlptl/pkg/registry"
 
 	// Client auth plugins! They will auto-init if we import them.
@@ -48,6 +55,9 @@ import (
 
 const clusterSpecConfigMap = "ctlptl-cluster-spec"
 
+// The key in the cluster spec configmap that records when ctlptl created the cluster.
+const clusterSpecCreatedAtKey = "createdAt"
+
 var typeMeta = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "Cluster"}
 var listTypeMeta = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "ClusterList"}
 var groupResource = schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}
@@ -59,10 +69,16 @@ var groupResource = schema.GroupResource{Group: "ctlptl.dev", Resource: "cluster
 //
 // So our health check timeout is a bit longer than we'd like.
 // Fortunately, ctlptl is mostly used for local clusters.
+//
+// These are the defaults. Users can override them with flags,
+// or in the timeouts section of ~/.ctlptl/config.yaml.
 const healthCheckTimeout = 3 * time.Second
 
 const waitForKubeConfigTimeout = time.Minute
 const waitForClusterCreateTimeout = 5 * time.Minute
+const waitForDeploymentsTimeout = 5 * time.Minute
+const dockerDesktopStartTimeout = time.Minute
+const dockerDesktopRestartTimeout = 2 * time.Minute
 
 func TypeMeta() api.TypeMeta {
 	return typeMeta
@@ -76,12 +92,21 @@ type configLoader func() (clientcmdapi.Config, error)
 type registryController interface {
 	Apply(ctx context.Context, r *api.Registry) (*api.Registry, error)
 	List(ctx context.Context, options registry.ListOptions) (*api.RegistryList, error)
+	PushTestImage(ctx context.Context, name string) (string, error)
+	Stop(ctx context.Context, name string) error
+	Start(ctx context.Context, name string) (*api.Registry, error)
+	Delete(ctx context.Context, name string) error
 }
 
 type clientLoader func(*rest.Config) (kubernetes.Interface, error)
 
+type dynamicClientLoader func(*rest.Config) (dynamic.Interface, meta.RESTMapper, error)
+
 type socatController interface {
 	ConnectRemoteDockerPort(ctx context.Context, port int) error
+	LocalPortforwarderPorts() ([]int, error)
+	StoppedRemotePortforwarders(ctx context.Context) ([]string, error)
+	StopLocalPortforwarder(ctx context.Context, port int) error
 }
 
 type Controller struct {
@@ -96,9 +121,17 @@ type Controller struct {
 	configWriter                configWriter
 	registryCtl                 registryController
 	clientLoader                clientLoader
+	dynamicClientLoader         dynamicClientLoader
 	socat                       socatController
+	lookPath                    func(file string) (string, error)
 	waitForKubeConfigTimeout    time.Duration
 	waitForClusterCreateTimeout time.Duration
+	waitForDeploymentsTimeout   time.Duration
+	healthCheckTimeout          time.Duration
+	dockerDesktopStartTimeout   time.Duration
+	dockerDesktopRestartTimeout time.Duration
+	keepOnFailure               bool
+	progress                    progress.Reporter
 	os                          string
 
 	// TODO(nick): I deeply regret making this struct use goroutines. It makes
@@ -120,7 +153,7 @@ func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, erro
 		return loader.RawConfig()
 	})
 
-	configWriter := kubeconfigWriter{iostreams: iostreams}
+	configWriter := lockedConfigWriter{w: kubeconfigWriter{iostreams: iostreams}}
 
 	clientLoader := clientLoader(func(restConfig *rest.Config) (kubernetes.Interface, error) {
 		return kubernetes.NewForConfig(restConfig)
@@ -131,7 +164,12 @@ func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, erro
 		return nil, err
 	}
 
-	return &Controller{
+	globalConfig, err := ctlptlconfig.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Controller{
 		iostreams:                   iostreams,
 		runner:                      exec.RealCmdRunner{},
 		config:                      config,
@@ -140,10 +178,70 @@ func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, erro
 		admins:                      make(map[clusterid.Product]Admin),
 		configLoader:                configLoader,
 		clientLoader:                clientLoader,
+		dynamicClientLoader:         defaultDynamicClientLoader,
+		lookPath:                    osexec.LookPath,
 		waitForKubeConfigTimeout:    waitForKubeConfigTimeout,
 		waitForClusterCreateTimeout: waitForClusterCreateTimeout,
+		waitForDeploymentsTimeout:   waitForDeploymentsTimeout,
+		healthCheckTimeout:          healthCheckTimeout,
+		dockerDesktopStartTimeout:   dockerDesktopStartTimeout,
+		dockerDesktopRestartTimeout: dockerDesktopRestartTimeout,
+		progress:                    progress.Text,
 		os:                          runtime.GOOS,
-	}, nil
+	}
+	c.SetTimeouts(globalConfig.Timeouts)
+	return c, nil
+}
+
+// Overrides the controller's timeouts with the ones that are set.
+func (c *Controller) SetTimeouts(t ctlptlconfig.Timeouts) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := ctlptlconfig.Timeouts{
+		Create:               c.waitForClusterCreateTimeout,
+		KubeConfig:           c.waitForKubeConfigTimeout,
+		HealthCheck:          c.healthCheckTimeout,
+		Deployments:          c.waitForDeploymentsTimeout,
+		DockerDesktopStart:   c.dockerDesktopStartTimeout,
+		DockerDesktopRestart: c.dockerDesktopRestartTimeout,
+	}.Merge(t)
+	c.waitForClusterCreateTimeout = merged.Create
+	c.waitForKubeConfigTimeout = merged.KubeConfig
+	c.healthCheckTimeout = merged.HealthCheck
+	c.waitForDeploymentsTimeout = merged.Deployments
+	c.dockerDesktopStartTimeout = merged.DockerDesktopStart
+	c.dockerDesktopRestartTimeout = merged.DockerDesktopRestart
+	if c.dmachine != nil {
+		c.dmachine.startTimeout = merged.DockerDesktopStart
+		c.dmachine.restartTimeout = merged.DockerDesktopRestart
+	}
+}
+
+// When a create fails partway through, or is interrupted, Apply deletes
+// the half-created cluster. Set keep to leave it behind for debugging.
+func (c *Controller) SetKeepOnFailure(keep bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keepOnFailure = keep
+}
+
+// Reports each phase of creating and deleting clusters.
+func (c *Controller) SetProgress(r progress.Reporter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress = r
+}
+
+// Marks the start of a phase. Call the returned func when the phase ends.
+func (c *Controller) startPhase(phase progress.Phase, name string) func(err error) {
+	c.mu.Lock()
+	r := c.progress
+	c.mu.Unlock()
+	if r == nil {
+		r = progress.Text
+	}
+	return r.Start(phase, name)
 }
 
 func (c *Controller) getSocatController(ctx context.Context) (socatController, error) {
@@ -180,6 +278,23 @@ func (c *Controller) getDockerCLI(ctx context.Context) (dctr.CLI, error) {
 }
 
 func (c *Controller) machine(ctx context.Context, name string, product clusterid.Product) (Machine, error) {
+	switch product {
+	case clusterid.ProductDockerDesktop, clusterid.ProductKIND, clusterid.ProductK3D:
+		return c.dockerMachine(ctx)
+
+	case clusterid.ProductMinikube:
+		dmachine, err := c.dockerMachine(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newMinikubeMachine(c.iostreams, c.runner, name, dmachine), nil
+	}
+
+	return unknownMachine{product: product}, nil
+}
+
+// The machine that runs Docker, shared by all clusters.
+func (c *Controller) dockerMachine(ctx context.Context) (*dockerMachine, error) {
 	dockerCLI, err := c.getDockerCLI(ctx)
 	if err != nil {
 		return nil, err
@@ -188,29 +303,16 @@ func (c *Controller) machine(ctx context.Context, name string, product clusterid
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	switch product {
-	case clusterid.ProductDockerDesktop, clusterid.ProductKIND, clusterid.ProductK3D:
-		if c.dmachine == nil {
-			machine, err := NewDockerMachine(ctx, dockerCLI.Client(), c.iostreams)
-			if err != nil {
-				return nil, err
-			}
-			c.dmachine = machine
-		}
-		return c.dmachine, nil
-
-	case clusterid.ProductMinikube:
-		if c.dmachine == nil {
-			machine, err := NewDockerMachine(ctx, dockerCLI.Client(), c.iostreams)
-			if err != nil {
-				return nil, err
-			}
-			c.dmachine = machine
+	if c.dmachine == nil {
+		machine, err := NewDockerMachine(ctx, dockerCLI.Client(), c.iostreams)
+		if err != nil {
+			return nil, err
 		}
-		return newMinikubeMachine(c.iostreams, c.runner, name, c.dmachine), nil
+		machine.startTimeout = c.dockerDesktopStartTimeout
+		machine.restartTimeout = c.dockerDesktopRestartTimeout
+		c.dmachine = machine
 	}
-
-	return unknownMachine{product: product}, nil
+	return c.dmachine, nil
 }
 
 func (c *Controller) registryController(ctx context.Context) (registryController, error) {
@@ -224,7 +326,11 @@ func (c *Controller) registryController(ctx context.Context) (registryController
 
 	result := c.registryCtl
 	if result == nil {
-		result = registry.NewController(c.iostreams, dockerCLI)
+		regCtl := registry.NewController(c.iostreams, dockerCLI)
+		if c.progress != nil {
+			regCtl.SetProgress(c.progress)
+		}
+		result = regCtl
 		c.registryCtl = result
 	}
 	return result, nil
@@ -279,13 +385,15 @@ func (c *Controller) configCopy() *clientcmdapi.Config {
 	return c.config.DeepCopy()
 }
 
-// Gets the port of the current API server.
-func (c *Controller) currentAPIServerPort() int {
+// Gets the port of the named cluster's API server.
+//
+// Looks the cluster up by name rather than by the current context,
+// because other clusters may be switching the current context concurrently.
+func (c *Controller) apiServerPort(name string) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	current := c.config.CurrentContext
-	context, ok := c.config.Contexts[current]
+	context, ok := c.config.Contexts[name]
 	if !ok {
 		return 0
 	}
@@ -348,6 +456,7 @@ func (c *Controller) populateCreationTimestamp(ctx context.Context, cluster *api
 	}
 
 	cluster.Status.CreationTimestamp = minTime
+	cluster.Status.Nodes = len(nodes.Items)
 
 	return nil
 }
@@ -425,18 +534,35 @@ func (c *Controller) populateClusterSpec(ctx context.Context, cluster *api.Clust
 		return err
 	}
 
+	cluster.Labels = spec.Labels
+	cluster.TTL = spec.TTL
+	cluster.Images = spec.Images
+	cluster.Manifests = spec.Manifests
+	cluster.Wait = spec.Wait
 	cluster.KubernetesVersion = spec.KubernetesVersion
 	cluster.MinCPUs = spec.MinCPUs
 	cluster.KindV1Alpha4Cluster = spec.KindV1Alpha4Cluster
 	cluster.Minikube = spec.Minikube
 	cluster.K3D = spec.K3D
+
+	if spec.TTL != "" {
+		ttl, err := time.ParseDuration(spec.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid ttl %q: %v", spec.TTL, err)
+		}
+		createdAt, err := time.Parse(time.RFC3339, cMap.Data[clusterSpecCreatedAtKey])
+		if err != nil {
+			return fmt.Errorf("invalid creation time %q: %v", cMap.Data[clusterSpecCreatedAtKey], err)
+		}
+		cluster.Status.ExpirationTimestamp = &metav1.Time{Time: createdAt.Add(ttl)}
+	}
 	return nil
 }
 
 // If you have dead clusters in your kubeconfig, it's common for the requests to
 // hang indefinitely. So we do a quick health check with a short timeout.
 func (c *Controller) healthCheckCluster(ctx context.Context, client kubernetes.Interface) (*version.Info, error) {
-	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.healthCheckTimeout)
 	defer cancel()
 
 	return c.serverVersion(ctx, client)
@@ -473,7 +599,7 @@ func (c *Controller) populateCluster(ctx context.Context, cluster *api.Cluster)
 	name := cluster.Name
 	product := clusterid.Product(cluster.Product)
 	if product == clusterid.ProductKIND || product == clusterid.ProductK3D || product == clusterid.ProductMinikube {
-		err := c.maybeCreateForwarderForCurrentCluster(ctx, io.Discard)
+		err := c.maybeCreateForwarderForCluster(ctx, name, io.Discard)
 		if err != nil {
 			// If creating the forwarder fails, that's OK. We may still be able to populate things.
 			klog.V(4).Infof("WARNING: connecting socat tunnel to cluster %s: %v\n", name, err)
@@ -562,6 +688,10 @@ func supportsRegistry(product clusterid.Product) bool {
 	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube || product == clusterid.ProductK3D
 }
 
+func supportsImageLoad(product clusterid.Product) bool {
+	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube || product == clusterid.ProductK3D
+}
+
 func supportsKubernetesVersion(product clusterid.Product, version string) bool {
 	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube
 }
@@ -642,42 +772,67 @@ func (c *Controller) deleteIfIrreconcilable(ctx context.Context, desired, existi
 	return nil
 }
 
-// Checks if a registry exists with the given name, and creates one if it doesn't.
-func (c *Controller) ensureRegistryExistsForCluster(ctx context.Context, desired *api.Cluster) (*api.Registry, error) {
-	regName := desired.Registry
-	if regName == "" {
-		return nil, nil
+// Returns the registry that the cluster connects to, or nil if it doesn't have one.
+func RegistryFor(cluster *api.Cluster) *api.Registry {
+	if cluster.Registry == "" {
+		return nil
 	}
 
 	regLabels := map[string]string{}
-	if desired.Product == string(clusterid.ProductK3D) {
+	if cluster.Product == string(clusterid.ProductK3D) {
 		// A K3D cluster will only connect to a registry
 		// with these labels.
 		regLabels["app"] = "k3d"
 		regLabels["k3d.role"] = "registry"
 	}
 
+	return &api.Registry{
+		TypeMeta: registry.TypeMeta(),
+		Name:     cluster.Registry,
+		Labels:   regLabels,
+	}
+}
+
+// Checks if a registry exists with the given name, and creates one if it doesn't.
+// Returns the cluster's registry, and whether we had to create it.
+func (c *Controller) ensureRegistryExistsForCluster(ctx context.Context, desired *api.Cluster) (*api.Registry, bool, error) {
+	desiredReg := RegistryFor(desired)
+	if desiredReg == nil {
+		return nil, false, nil
+	}
+
 	regCtl, err := c.registryController(ctx)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return regCtl.Apply(ctx, &api.Registry{
-		TypeMeta: registry.TypeMeta(),
-		Name:     regName,
-		Labels:   regLabels,
-	})
+	existing, err := regCtl.List(ctx, registry.ListOptions{FieldSelector: fmt.Sprintf("name=%s", desiredReg.Name)})
+	if err != nil {
+		return nil, false, err
+	}
+
+	reg, err := regCtl.Apply(ctx, desiredReg)
+	if err != nil {
+		return nil, false, err
+	}
+	return reg, len(existing.Items) == 0, nil
 }
 
 // Compare the desired cluster against the existing cluster, and reconcile
 // the two to match.
-func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Cluster, error) {
+//
+// If creating the cluster fails after the cluster tool has started,
+// deletes the half-created cluster, unless SetKeepOnFailure is on.
+func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (_ *api.Cluster, retErr error) {
 	if desired.Product == "" {
 		return nil, fmt.Errorf("product field must be non-empty")
 	}
 	if desired.Registry != "" && !supportsRegistry(clusterid.Product(desired.Product)) {
 		return nil, fmt.Errorf("product %s does not support a registry", desired.Product)
 	}
+	if len(desired.Images) > 0 && !supportsImageLoad(clusterid.Product(desired.Product)) {
+		return nil, fmt.Errorf("product %s does not support preloading images", desired.Product)
+	}
 	if desired.KubernetesVersion != "" && !supportsKubernetesVersion(clusterid.Product(desired.Product), desired.KubernetesVersion) {
 		return nil, fmt.Errorf("product %s does not support a custom Kubernetes version", desired.Product)
 	}
@@ -690,9 +845,26 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	if desired.K3D != nil && clusterid.Product(desired.Product) != clusterid.ProductK3D {
 		return nil, fmt.Errorf("k3d config may only be set on clusters with product: k3d. Actual product: %s", desired.Product)
 	}
+	if desired.TTL != "" {
+		ttl, err := time.ParseDuration(desired.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid ttl %q: must be a positive duration, like 8h", desired.TTL)
+		}
+	}
+
+	err := validateManifests(desired.Manifests)
+	if err != nil {
+		return nil, err
+	}
 
 	FillDefaults(desired)
 
+	// Check for missing tools up front, rather than failing halfway through.
+	err = c.preflight(ctx, clusterid.Product(desired.Product))
+	if err != nil {
+		return nil, err
+	}
+
 	// Fetch the machine driver for this product and cluster name,
 	// and use it to apply the constraints to the underlying VM.
 	machine, err := c.machine(ctx, desired.Name, clusterid.Product(desired.Product))
@@ -702,7 +874,9 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 
 	// First, we have to make sure the machine driver has started, so that we can
 	// query it at all for the existing configuration.
+	done := c.startPhase(progress.PhaseMachineEnsure, desired.Name)
 	err = machine.EnsureExists(ctx)
+	done(err)
 	if err != nil {
 		return nil, err
 	}
@@ -743,13 +917,15 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	needsRestart := existingStatus.CreationTimestamp.Time.IsZero() ||
 		existingStatus.CPUs < desired.MinCPUs
 	if needsRestart {
+		done := c.startPhase(progress.PhaseRestart, desired.Name)
 		err := machine.Restart(ctx, desired, existingCluster)
+		done(err)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	reg, err := c.ensureRegistryExistsForCluster(ctx, desired)
+	reg, regCreated, err := c.ensureRegistryExistsForCluster(ctx, desired)
 	if err != nil {
 		return nil, err
 	}
@@ -758,25 +934,33 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	needsCreate := existingStatus.CreationTimestamp.Time.IsZero() ||
 		desired.Name != existingCluster.Name ||
 		desired.Product != existingCluster.Product
+	if needsCreate && !c.hasClusterContainers(ctx, desired.Name) {
+		// Only roll back clusters that we're sure we started, so that we
+		// never delete a cluster that merely lost its kubeconfig context.
+		defer func() {
+			if retErr != nil {
+				retErr = c.rollbackCreate(ctx, admin, desired, regCreated, retErr)
+			}
+		}()
+	}
 	if needsCreate {
+		done := c.startPhase(progress.PhaseCreate, desired.Name)
 		err := admin.Create(ctx, desired, reg)
+		done(err)
 		if err != nil {
 			return nil, err
 		}
 
+		done = c.startPhase(progress.PhaseWaitContext, desired.Name)
 		err = c.waitForContextCreate(ctx, desired)
+		done(err)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Update the kubectl context to match this cluster.
-	err = c.configWriter.SetContext(desired.Name)
-	if err != nil {
-		return nil, fmt.Errorf("switching to cluster context %s: %v", desired.Name, err)
-	}
-
-	err = c.reloadConfigs()
+	err = c.UseContext(desired.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -784,7 +968,7 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	if needsCreate {
 		// If the cluster apiserver is in a remote docker cluster,
 		// set up a portforwarder.
-		err := c.maybeCreateForwarderForCurrentCluster(ctx, c.iostreams.ErrOut)
+		err := c.maybeCreateForwarderForCluster(ctx, desired.Name, c.iostreams.ErrOut)
 		if err != nil {
 			return nil, err
 		}
@@ -794,22 +978,92 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 			return nil, err
 		}
 
-		err = c.waitForHealthCheckAfterCreate(ctx, desired)
+		done := c.startPhase(progress.PhaseWaitHealth, desired.Name)
+		err = c.waitForGates(ctx, desired, gatesBeforeManifests(desired))
+		done(err)
 		if err != nil {
 			return nil, err
 		}
 
-		err = c.writeClusterSpec(ctx, desired)
+		done = c.startPhase(progress.PhaseWriteSpec, desired.Name)
+		err = c.writeClusterSpec(ctx, desired, false)
+		done(err)
 		if err != nil {
 			return nil, errors.Wrap(err, "configuring cluster")
 		}
 
 		if desired.Registry != "" {
+			done := c.startPhase(progress.PhaseRegistryHosting, desired.Name)
 			err = c.createRegistryHosting(ctx, admin, desired, reg)
+			done(err)
 			if err != nil {
 				return nil, errors.Wrap(err, "configuring cluster registry")
 			}
 		}
+
+		if len(desired.Images) > 0 {
+			done := c.startPhase(progress.PhaseLoadImages, desired.Name)
+			err = c.loadImages(ctx, admin, desired, desired.Images)
+			done(err)
+			if err != nil {
+				return nil, errors.Wrap(err, "preloading images")
+			}
+		}
+	} else if !maps.Equal(desired.Labels, existingCluster.Labels) ||
+		desired.TTL != existingCluster.TTL ||
+		!slices.Equal(desired.Manifests, existingCluster.Manifests) ||
+		!slices.Equal(desired.Images, existingCluster.Images) {
+		// Labels, TTLs, manifests, and images only live in the cluster spec, so
+		// we can change them without re-creating the cluster. Applying a cluster
+		// without labels, a TTL, manifests, or images removes them from the spec.
+		newImages := []string{}
+		for _, image := range desired.Images {
+			if !slices.Contains(existingCluster.Images, image) {
+				newImages = append(newImages, image)
+			}
+		}
+		if len(newImages) > 0 {
+			done := c.startPhase(progress.PhaseLoadImages, desired.Name)
+			err = c.loadImages(ctx, admin, desired, newImages)
+			done(err)
+			if err != nil {
+				return nil, errors.Wrap(err, "preloading images")
+			}
+		}
+
+		updated := existingCluster.DeepCopy()
+		updated.TTL = desired.TTL
+		updated.Manifests = desired.Manifests
+		updated.Images = desired.Images
+		updated.Labels = desired.Labels
+		done := c.startPhase(progress.PhaseWriteSpec, desired.Name)
+		err = c.writeClusterSpec(ctx, updated, true)
+		done(err)
+		if err != nil {
+			return nil, errors.Wrap(err, "updating cluster spec")
+		}
+	}
+
+	// Server-side apply is idempotent, so we re-apply the manifests every time
+	// to pick up any changes to them.
+	if len(desired.Manifests) > 0 {
+		done := c.startPhase(progress.PhaseApplyManifests, desired.Name)
+		err = c.applyManifests(ctx, desired)
+		done(err)
+		if err != nil {
+			return nil, errors.Wrap(err, "applying manifests")
+		}
+
+		// Now that any CNI in the manifests is installed,
+		// wait for the gates that we skipped above.
+		if needsCreate {
+			done := c.startPhase(progress.PhaseWaitHealth, desired.Name)
+			err = c.waitForHealthCheckAfterCreate(ctx, desired)
+			done(err)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return c.Get(ctx, desired.Name)
@@ -817,7 +1071,11 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 
 // Writes the cluster spec to the cluster itself, so
 // we can read it later to determine how the cluster was initialized.
-func (c *Controller) writeClusterSpec(ctx context.Context, cluster *api.Cluster) error {
+//
+// Also records when ctlptl created the cluster, so that we can tell when its
+// TTL expires. When updating the spec of an existing cluster, keepCreatedAt
+// preserves the recorded time.
+func (c *Controller) writeClusterSpec(ctx context.Context, cluster *api.Cluster, keepCreatedAt bool) error {
 	client, err := c.client(cluster.Name)
 	if err != nil {
 		return err
@@ -830,6 +1088,17 @@ func (c *Controller) writeClusterSpec(ctx context.Context, cluster *api.Cluster)
 		return err
 	}
 
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	if keepCreatedAt {
+		existing, err := client.CoreV1().ConfigMaps("kube-public").Get(ctx, clusterSpecConfigMap, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && existing.Data[clusterSpecCreatedAtKey] != "" {
+			createdAt = existing.Data[clusterSpecCreatedAtKey]
+		}
+	}
+
 	err = client.CoreV1().ConfigMaps("kube-public").Delete(ctx, clusterSpecConfigMap, metav1.DeleteOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
@@ -840,7 +1109,10 @@ func (c *Controller) writeClusterSpec(ctx context.Context, cluster *api.Cluster)
 			Name:      clusterSpecConfigMap,
 			Namespace: "kube-public",
 		},
-		Data: map[string]string{"cluster.v1alpha1": string(data)},
+		Data: map[string]string{
+			"cluster.v1alpha1":      string(data),
+			clusterSpecCreatedAtKey: createdAt,
+		},
 	}, metav1.CreateOptions{})
 	return err
 }
@@ -894,7 +1166,9 @@ func (c *Controller) Delete(ctx context.Context, name string) error {
 		return err
 	}
 
+	done := c.startPhase(progress.PhaseDelete, existing.Name)
 	err = admin.Delete(ctx, existing)
+	done(err)
 	if err != nil {
 		return err
 	}
@@ -912,6 +1186,15 @@ func (c *Controller) Delete(ctx context.Context, name string) error {
 	return nil
 }
 
+// Switches the kubectl context to the named cluster.
+func (c *Controller) UseContext(name string) error {
+	err := c.configWriter.SetContext(name)
+	if err != nil {
+		return fmt.Errorf("switching to cluster context %s: %v", name, err)
+	}
+	return c.reloadConfigs()
+}
+
 func (c *Controller) reloadConfigs() error {
 	config, err := c.configLoader()
 	if err != nil {
@@ -956,13 +1239,13 @@ func (c *Controller) Get(ctx context.Context, name string) (*api.Cluster, error)
 }
 
 func (c *Controller) List(ctx context.Context, options ListOptions) (*api.ClusterList, error) {
-	selector, err := fields.ParseSelector(options.FieldSelector)
+	selectors, err := parseSelectors(options)
 	if err != nil {
 		return nil, err
 	}
 
 	config := c.configCopy()
-	names := make([]string, 0, len(c.config.Contexts))
+	names := make([]string, 0, len(config.Contexts))
 	for name, ct := range config.Contexts {
 		_, ok := config.Clusters[ct.Cluster]
 		if !ok {
@@ -979,17 +1262,25 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Cluste
 	g, ctx := errgroup.WithContext(ctx)
 
 	for i, name := range names {
-		ct := c.config.Contexts[name]
+		ct := config.Contexts[name]
 		g.Go(func() error {
 			cluster := &api.Cluster{
 				TypeMeta: typeMeta,
 				Name:     name,
 				Product:  clusterid.ProductFromContext(ct, config.Clusters[ct.Cluster]).String(),
 			}
-			if !selector.Matches((*clusterFields)(cluster)) {
+			if !selectors.spec.Matches((*clusterFields)(cluster)) {
 				return nil
 			}
 			c.populateCluster(ctx, cluster)
+
+			// Status and labels aren't known until the cluster is populated.
+			if !selectors.status.Empty() && !selectors.status.Matches((*clusterFields)(cluster)) {
+				return nil
+			}
+			if !selectors.labels.Empty() && !selectors.labels.Matches(labels.Set(cluster.Labels)) {
+				return nil
+			}
 			all[i] = cluster
 			return nil
 		})
@@ -1014,9 +1305,9 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Cluste
 	}, nil
 }
 
-// If the current cluster is on a remote docker instance,
+// If the cluster is on a remote docker instance,
 // we need a port-forwarder to connect it.
-func (c *Controller) maybeCreateForwarderForCurrentCluster(ctx context.Context, errOut io.Writer) error {
+func (c *Controller) maybeCreateForwarderForCluster(ctx context.Context, name string, errOut io.Writer) error {
 	dockerCLI, err := c.getDockerCLI(ctx)
 	if err != nil {
 		return err
@@ -1026,7 +1317,7 @@ func (c *Controller) maybeCreateForwarderForCurrentCluster(ctx context.Context,
 		return nil
 	}
 
-	port := c.currentAPIServerPort()
+	port := c.apiServerPort(name)
 	if port == 0 {
 		return nil
 	}
@@ -1083,26 +1374,17 @@ func (c *Controller) waitForContextCreate(ctx context.Context, cluster *api.Clus
 // https://github.com/tilt-dev/ctlptl/issues/87
 // https://github.com/tilt-dev/ctlptl/issues/131
 func (c *Controller) waitForHealthCheckAfterCreate(ctx context.Context, cluster *api.Cluster) error {
+	return c.waitForGates(ctx, cluster, waitGates(cluster))
+}
+
+// Polls until the cluster is healthy and passes the given readiness gates.
+func (c *Controller) waitForGates(ctx context.Context, cluster *api.Cluster, gates api.ClusterWait) error {
 	checkOK := func() error {
 		client, err := c.client(cluster.Name)
 		if err != nil {
 			return err
 		}
-
-		// quick apiserver health check.
-		_, err = c.healthCheckCluster(ctx, client)
-		if err != nil {
-			return err
-		}
-
-		// make sure the kube-public namespace exists,
-		// because this is where ctlptl writes its configs.
-		_, err = client.CoreV1().Namespaces().Get(ctx, "kube-public", metav1.GetOptions{})
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return c.checkReady(ctx, client, gates)
 	}
 
 	// If the tool properly waited for the cluster to init,
@@ -1132,7 +1414,12 @@ func (c *Controller) waitForHealthCheckAfterCreate(ctx context.Context, cluster
 // currently running inside a container and the cluster admin object supports
 // the modifications.
 func (c *Controller) maybeFixKubeConfigInsideContainer(ctx context.Context, cluster *api.Cluster) error {
-	containerID := insideContainer(ctx, c.dockerCLI.Client())
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return err
+	}
+
+	containerID := dctr.InsideContainer(ctx, dockerCLI.Client())
 	if containerID == "" {
 		return nil
 	}
@@ -1147,7 +1434,7 @@ func (c *Controller) maybeFixKubeConfigInsideContainer(ctx context.Context, clus
 		return nil
 	}
 
-	err = adminInC.ModifyConfigInContainer(ctx, cluster, containerID, c.dockerCLI.Client(), c.configWriter)
+	err = adminInC.ModifyConfigInContainer(ctx, cluster, containerID, dockerCLI.Client(), c.configWriter)
 	if err != nil {
 		return fmt.Errorf("error updating kube config: %w", err)
 	}
@@ -4,11 +4,20 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"iter"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/distribution/reference"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/api/types/jsonstream"
 	"github.com/moby/moby/api/types/system"
 	"github.com/moby/moby/client"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +25,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -32,6 +42,8 @@ import (
 	"github.com/tilt-dev/ctlptl/internal/dctr"
 	"github.com/tilt-dev/ctlptl/internal/exec"
 	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/config"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
 	"github.com/tilt-dev/ctlptl/pkg/registry"
 )
 
@@ -111,6 +123,144 @@ func TestClusterListSelectorNoMatch(t *testing.T) {
 	assert.Equal(t, 0, len(clusters.Items))
 }
 
+func TestClusterListStatusSelector(t *testing.T) {
+	c := newFakeController(t)
+	clusters, err := c.List(context.Background(), ListOptions{FieldSelector: "status.current=true"})
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, "microk8s", clusters.Items[0].Name)
+
+	clusters, err = c.List(context.Background(), ListOptions{FieldSelector: "product=docker-desktop,status.current!=true"})
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, "docker-desktop", clusters.Items[0].Name)
+}
+
+func TestClusterListUnsupportedSelector(t *testing.T) {
+	c := newFakeController(t)
+	_, err := c.List(context.Background(), ListOptions{FieldSelector: "status.cpus=4"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unsupported field "status.cpus"`)
+	}
+}
+
+func TestClusterListLabelSelector(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.fakeK8s.CoreV1().ConfigMaps("kube-public").Create(context.Background(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterSpecConfigMap, Namespace: "kube-public"},
+		Data:       map[string]string{"cluster.v1alpha1": "labels:\n  team: payments\n"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	clusters, err := f.controller.List(context.Background(), ListOptions{
+		FieldSelector: "name=microk8s",
+		LabelSelector: "team=payments",
+	})
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, map[string]string{"team": "payments"}, clusters.Items[0].Labels)
+
+	clusters, err = f.controller.List(context.Background(), ListOptions{LabelSelector: "team=infra"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(clusters.Items))
+}
+
+func TestClusterDescribe(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.fakeK8s.CoreV1().Nodes().Update(ctx, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+			Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "172.18.0.2"}},
+			NodeInfo: v1.NodeSystemInfo{
+				KubeletVersion:          "v1.27.3",
+				ContainerRuntimeVersion: "containerd://1.7.1",
+			},
+		},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i, e := range []v1.Event{
+		{Type: v1.EventTypeWarning, Reason: "BackOff", LastTimestamp: metav1.Time{Time: now.Add(-time.Minute)}},
+		{Type: v1.EventTypeNormal, Reason: "Pulled", LastTimestamp: metav1.Time{Time: now}},
+		{Type: v1.EventTypeWarning, Reason: "FailedMount", LastTimestamp: metav1.Time{Time: now.Add(-time.Hour)}},
+	} {
+		e.ObjectMeta = metav1.ObjectMeta{Name: fmt.Sprintf("event-%d", i), Namespace: "kube-system"}
+		_, err := f.fakeK8s.CoreV1().Events("kube-system").Create(ctx, &e, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	desc, err := f.controller.Describe(ctx, "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", desc.Cluster.Name)
+	assert.Equal(t, []NodeDescription{{
+		Name:             "node-1",
+		Ready:            "True",
+		Roles:            []string{"control-plane"},
+		KubeletVersion:   "v1.27.3",
+		ContainerRuntime: "containerd://1.7.1",
+		InternalIP:       "172.18.0.2",
+	}}, desc.Nodes)
+
+	reasons := []string{}
+	for _, e := range desc.Events {
+		reasons = append(reasons, e.Reason)
+	}
+	assert.Equal(t, []string{"FailedMount", "BackOff"}, reasons)
+	assert.False(t, desc.RemoteDocker)
+}
+
+func TestClusterVerify(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.fakeK8s.CoreV1().ConfigMaps("kube-public").Create(ctx, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-registry-hosting", Namespace: "kube-public"},
+		Data:       map[string]string{"localRegistryHosting.v1": "host: localhost:5005\n"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	f.registryCtl.lastApply = &api.Registry{
+		Name:   "kind-registry",
+		Status: api.RegistryStatus{HostPort: 5005, ContainerPort: 5000},
+	}
+
+	execs := [][]string{}
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		execs = append(execs, argv)
+		return ""
+	})
+
+	v, err := f.controller.Verify(ctx, "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastPush)
+	assert.Equal(t, &Verification{
+		Cluster:  "microk8s",
+		Registry: "kind-registry",
+		Image:    "ctlptl-verify:latest",
+		Results: []PullResult{
+			{Node: "node-1", Image: "localhost:5005/ctlptl-verify:latest"},
+			{Node: "node-1", Image: "kind-registry:5000/ctlptl-verify:latest"},
+		},
+	}, v)
+	assert.False(t, v.Failed())
+	assert.Equal(t, [][]string{
+		{"docker", "exec", "node-1", "crictl", "pull", "localhost:5005/ctlptl-verify:latest"},
+		{"docker", "exec", "node-1", "crictl", "pull", "kind-registry:5000/ctlptl-verify:latest"},
+	}, execs)
+}
+
+func TestClusterVerifyNoRegistry(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.Verify(context.Background(), "microk8s")
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster microk8s has no registry", err.Error())
+	}
+}
+
 func TestClusterGetMissing(t *testing.T) {
 	c := newFakeController(t)
 	_, err := c.Get(context.Background(), "dunkees")
@@ -135,6 +285,203 @@ func TestClusterApplyKIND(t *testing.T) {
 	assert.Equal(t, "kind-kind", result.Name)
 }
 
+func TestClusterApplyKINDConcurrent(t *testing.T) {
+	lock := &fakeKubeconfigLock{}
+	var fixtures []*fixture
+	var admins []*fakeAdmin
+	for i := 0; i < 4; i++ {
+		f := newFixture(t)
+		f.setOS("darwin")
+		f.configWriter.lock = lock
+		f.controller.configWriter = lockedConfigWriter{w: f.configWriter}
+		fixtures = append(fixtures, f)
+		admins = append(admins, f.newFakeAdmin(clusterid.ProductKIND))
+	}
+
+	errs := make([]error, len(fixtures))
+	var wg sync.WaitGroup
+	for i, f := range fixtures {
+		wg.Go(func() {
+			_, errs[i] = f.controller.Apply(context.Background(), &api.Cluster{
+				Product: string(clusterid.ProductKIND),
+			})
+		})
+	}
+	wg.Wait()
+
+	for i := range fixtures {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "kind-kind", admins[i].created.Name)
+		assert.Nil(t, admins[i].deleted, "cluster should not be rolled back")
+	}
+}
+
+func TestClusterApplyKINDProgress(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	reporter := &fakeReporter{}
+	f.controller.SetProgress(reporter)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"start machine-ensure kind-kind",
+		"end machine-ensure <nil>",
+		"start restart kind-kind",
+		"end restart <nil>",
+		"start create kind-kind",
+		"end create <nil>",
+		"start wait-context kind-kind",
+		"end wait-context <nil>",
+		"start wait-health kind-kind",
+		"end wait-health <nil>",
+		"start write-spec kind-kind",
+		"end write-spec <nil>",
+	}, reporter.events)
+}
+
+func TestClusterApplyLabels(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"team": "payments"},
+	})
+	require.NoError(t, err)
+	kindAdmin.created = nil
+
+	// Adding a label doesn't re-create the cluster.
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"team": "payments", "env": "dev"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, map[string]string{"team": "payments", "env": "dev"}, result.Labels)
+
+	// Neither does removing one.
+	result, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"env": "dev"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, map[string]string{"env": "dev"}, result.Labels)
+}
+
+func TestClusterApplyTTL(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		TTL:     "8h",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "8h", result.TTL)
+	require.NotNil(t, result.Status.ExpirationTimestamp)
+	expires := result.Status.ExpirationTimestamp.Time
+	assert.WithinDuration(t, time.Now().Add(8*time.Hour), expires, time.Minute)
+	kindAdmin.created = nil
+
+	// Changing the TTL doesn't re-create the cluster,
+	// and counts from when the cluster was created.
+	result, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		TTL:     "1h",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, "1h", result.TTL)
+	assert.Equal(t, expires.Add(-7*time.Hour), result.Status.ExpirationTimestamp.Time)
+
+	// Applying the cluster without a TTL removes it.
+	result, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, "", result.TTL)
+	assert.Nil(t, result.Status.ExpirationTimestamp)
+}
+
+func TestClusterApplyImages(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.dockerClient.images = []image.Summary{{RepoTags: []string{"redis:7"}}}
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Images:  []string{"postgres:16", "redis:7"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres:16"}, f.dockerClient.pulls)
+	assert.Equal(t, []string{"postgres:16", "redis:7"}, kindAdmin.loadedImages)
+	assert.Equal(t, []string{"postgres:16", "redis:7"}, result.Images)
+
+	// Images that are already loaded aren't loaded again.
+	kindAdmin.loadedImages = nil
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Images:  []string{"postgres:16", "redis:7"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.loadedImages)
+
+	// New images are loaded without re-creating the cluster.
+	kindAdmin.created = nil
+	result, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Images:  []string{"postgres:16", "redis:7", "nginx:1"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, []string{"nginx:1"}, kindAdmin.loadedImages)
+	assert.Equal(t, []string{"postgres:16", "redis:7", "nginx:1"}, result.Images)
+}
+
+func TestClusterApplyImagesUnsupported(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductDockerDesktop),
+		Images:  []string{"postgres:16"},
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "product docker-desktop does not support preloading images", err.Error())
+	}
+}
+
+func TestClusterLoadImages(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	_, err := f.controller.LoadImages(context.Background(), "microk8s", []string{"postgres:16"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres:16"}, f.dockerClient.pulls)
+	assert.Equal(t, []string{"postgres:16"}, admin.loadedImages)
+}
+
+func TestClusterApplyInvalidTTL(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		TTL:     "-1h",
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid ttl "-1h": must be a positive duration, like 8h`, err.Error())
+	}
+}
+
 // Make sure an empty context doesn't confuse ctlptl.
 func TestClusterApplyKINDEmptyConfig(t *testing.T) {
 	f := newFixture(t)
@@ -265,9 +612,15 @@ func TestClusterApplyDockerDesktopNoRestart(t *testing.T) {
 
 	assert.Equal(t, 0, f.d4m.settingsWriteCount)
 
-	// Pretend the cluster isn't running.
-	err := f.fakeK8s.Tracker().Delete(schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}, "", "node-1")
+	// Pretend the cluster isn't running, until Docker Desktop resets it.
+	nodes := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+	node, err := f.fakeK8s.Tracker().Get(nodes, "", "node-1")
+	require.NoError(t, err)
+	err = f.fakeK8s.Tracker().Delete(nodes, "", "node-1")
 	assert.NoError(t, err)
+	f.d4m.onReset = func() {
+		assert.NoError(t, f.fakeK8s.Tracker().Add(node))
+	}
 	f.apply(clusterid.ProductDockerDesktop, 0)
 	assert.Equal(t, 1, f.d4m.settingsWriteCount)
 	assert.Equal(t, 1, f.d4m.resetCount)
@@ -275,6 +628,23 @@ func TestClusterApplyDockerDesktopNoRestart(t *testing.T) {
 	assert.Equal(t, 1, f.d4m.settingsWriteCount)
 }
 
+func TestClusterSetTimeouts(t *testing.T) {
+	f := newFixture(t)
+
+	f.controller.SetTimeouts(config.Timeouts{
+		Create:               10 * time.Minute,
+		HealthCheck:          10 * time.Second,
+		DockerDesktopRestart: 5 * time.Minute,
+	})
+
+	// Unset timeouts keep their old values.
+	assert.Equal(t, 10*time.Minute, f.controller.waitForClusterCreateTimeout)
+	assert.Equal(t, time.Millisecond, f.controller.waitForKubeConfigTimeout)
+	assert.Equal(t, 10*time.Second, f.controller.healthCheckTimeout)
+	assert.Equal(t, time.Second, f.dmachine.startTimeout)
+	assert.Equal(t, 5*time.Minute, f.dmachine.restartTimeout)
+}
+
 func TestClusterApplyMinikubeVersion(t *testing.T) {
 	f := newFixture(t)
 	f.setOS("darwin")
@@ -470,11 +840,13 @@ func newFixture(t *testing.T) *fixture {
 	dockerClient := &fakeDockerClient{host: "unix:///home/nick/.docker/desktop/docker.sock", ncpu: 1}
 	d4m := &fakeD4MClient{docker: dockerClient}
 	dmachine := &dockerMachine{
-		dockerClient: dockerClient,
-		iostreams:    genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr},
-		sleep:        func(d time.Duration) {},
-		d4m:          d4m,
-		os:           osName,
+		dockerClient:   dockerClient,
+		iostreams:      genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr},
+		sleep:          func(d time.Duration) {},
+		d4m:            d4m,
+		os:             osName,
+		startTimeout:   time.Second,
+		restartTimeout: time.Second,
 	}
 	config := &clientcmdapi.Config{
 		CurrentContext: "microk8s",
@@ -505,6 +877,9 @@ func newFixture(t *testing.T) *fixture {
 			Name:              "node-1",
 			CreationTimestamp: metav1.Time{Time: time.Now()},
 		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
 	}
 	ns := &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -512,7 +887,12 @@ func newFixture(t *testing.T) *fixture {
 			CreationTimestamp: metav1.Time{Time: time.Now()},
 		},
 	}
-	fakeK8s := fake.NewClientset(node, ns)
+	coreDNS := newDeployment("kube-system", "coredns", 1, 1)
+	coreDNS.Labels = map[string]string{"k8s-app": "kube-dns"}
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+	}
+	fakeK8s := fake.NewClientset(node, ns, coreDNS, sa)
 	clientLoader := clientLoader(func(restConfig *rest.Config) (kubernetes.Interface, error) {
 		return fakeK8s, nil
 	})
@@ -523,14 +903,19 @@ func newFixture(t *testing.T) *fixture {
 		runner:                      exec.NewFakeCmdRunner(func(argv []string) string { return "" }),
 		admins:                      make(map[clusterid.Product]Admin),
 		config:                      *config,
-		configWriter:                configWriter,
+		configWriter:                lockedConfigWriter{w: configWriter},
 		dmachine:                    dmachine,
 		configLoader:                configLoader,
 		clientLoader:                clientLoader,
 		clients:                     make(map[string]kubernetes.Interface),
 		registryCtl:                 registryCtl,
+		lookPath:                    func(file string) (string, error) { return "/usr/bin/" + file, nil },
 		waitForKubeConfigTimeout:    time.Millisecond,
 		waitForClusterCreateTimeout: time.Millisecond,
+		waitForDeploymentsTimeout:   time.Millisecond,
+		healthCheckTimeout:          time.Second,
+		dockerDesktopStartTimeout:   time.Second,
+		dockerDesktopRestartTimeout: time.Second,
 		os:                          osName,
 		dockerCLI:                   &fakeCLI{client: dockerClient},
 	}
@@ -590,6 +975,15 @@ type fakeDockerClient struct {
 	host        string
 	networks    []string
 	containerID string
+	containers  []container.Summary
+	stopped     []string
+	restarted   []string
+	images      []image.Summary
+	pulls       []string
+	commits     []string
+
+	// Containers that fail to commit, by name.
+	commitErrs map[string]error
 }
 
 func (c *fakeDockerClient) DaemonHost() string {
@@ -621,20 +1015,82 @@ func (d *fakeDockerClient) ContainerRemove(ctx context.Context, id string, optio
 }
 
 func (d *fakeDockerClient) ImagePull(ctx context.Context, image string, options client.ImagePullOptions) (client.ImagePullResponse, error) {
-	return nil, nil
+	d.pulls = append(d.pulls, image)
+	return fakePullResponse{ReadCloser: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+type fakePullResponse struct {
+	io.ReadCloser
+}
+
+func (r fakePullResponse) JSONMessages(ctx context.Context) iter.Seq2[jsonstream.Message, error] {
+	return func(yield func(jsonstream.Message, error) bool) {}
+}
+
+func (r fakePullResponse) Wait(ctx context.Context) error {
+	return nil
 }
 
 func (d *fakeDockerClient) ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
-	return client.ContainerListResult{}, nil
+	result := []container.Summary{}
+	for _, c := range d.containers {
+		match := true
+		for filter := range options.Filters["label"] {
+			k, v, _ := strings.Cut(filter, "=")
+			if c.Labels[k] != v {
+				match = false
+			}
+		}
+		if match {
+			result = append(result, c)
+		}
+	}
+	return client.ContainerListResult{Items: result}, nil
 }
 
 func (d *fakeDockerClient) ContainerCreate(ctx context.Context, options client.ContainerCreateOptions) (client.ContainerCreateResult, error) {
 	return client.ContainerCreateResult{}, nil
 }
 func (d *fakeDockerClient) ContainerStart(ctx context.Context, containerID string, options client.ContainerStartOptions) (client.ContainerStartResult, error) {
+	d.restarted = append(d.restarted, containerID)
 	return client.ContainerStartResult{}, nil
 }
 
+func (d *fakeDockerClient) ContainerCommit(ctx context.Context, containerID string, options client.ContainerCommitOptions) (client.ContainerCommitResult, error) {
+	if err := d.commitErrs[containerID]; err != nil {
+		return client.ContainerCommitResult{}, err
+	}
+	d.commits = append(d.commits, containerID)
+	return client.ContainerCommitResult{ID: "sha256:commit-" + containerID}, nil
+}
+
+func (d *fakeDockerClient) ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error) {
+	result := []image.Summary{}
+	for _, img := range d.images {
+		match := true
+		for filter := range options.Filters["label"] {
+			k, v, hasValue := strings.Cut(filter, "=")
+			if actual, ok := img.Labels[k]; !ok || (hasValue && actual != v) {
+				match = false
+			}
+		}
+		for ref := range options.Filters["reference"] {
+			if !slices.Contains(img.RepoTags, ref) {
+				match = false
+			}
+		}
+		if match {
+			result = append(result, img)
+		}
+	}
+	return client.ImageListResult{Items: result}, nil
+}
+
+func (d *fakeDockerClient) ContainerStop(ctx context.Context, containerID string, options client.ContainerStopOptions) (client.ContainerStopResult, error) {
+	d.stopped = append(d.stopped, containerID)
+	return client.ContainerStopResult{}, nil
+}
+
 func (d *fakeDockerClient) NetworkConnect(ctx context.Context, networkID string, options client.NetworkConnectOptions) (client.NetworkConnectResult, error) {
 	d.networks = append(d.networks, networkID)
 	return client.NetworkConnectResult{}, nil
@@ -654,7 +1110,11 @@ func (d *fakeDockerClient) NetworkDisconnect(ctx context.Context, networkID stri
 	return client.NetworkDisconnectResult{}, nil
 }
 
-func (d *fakeDockerClient) insideContainer(ctx context.Context) string {
+func (d *fakeDockerClient) VolumeRemove(ctx context.Context, volumeID string, options client.VolumeRemoveOptions) (client.VolumeRemoveResult, error) {
+	return client.VolumeRemoveResult{}, nil
+}
+
+func (d *fakeDockerClient) InsideContainer(ctx context.Context) string {
 	return d.containerID
 }
 
@@ -664,6 +1124,7 @@ type fakeD4MClient struct {
 	started            bool
 	settingsWriteCount int
 	resetCount         int
+	onReset            func()
 }
 
 func (c *fakeD4MClient) writeSettings(ctx context.Context, settings map[string]interface{}) error {
@@ -701,6 +1162,9 @@ func (c *fakeD4MClient) ensureMinCPU(settings map[string]interface{}, desired in
 
 func (c *fakeD4MClient) ResetCluster(ctx context.Context) error {
 	c.resetCount++
+	if c.onReset != nil {
+		c.onReset()
+	}
 	return nil
 }
 
@@ -717,6 +1181,10 @@ type fakeAdmin struct {
 	created         *api.Cluster
 	createdRegistry *api.Registry
 	deleted         *api.Cluster
+	stopped         *api.Cluster
+	started         *api.Cluster
+	loadedImages    []string
+	createErr       error
 	config          *clientcmdapi.Config
 	fakeK8s         *fake.Clientset
 }
@@ -740,7 +1208,12 @@ func (a *fakeAdmin) Create(ctx context.Context, config *api.Cluster, registry *a
 	a.fakeK8s.Discovery().(*discoveryfake.FakeDiscovery).FakedServerVersion = &version.Info{
 		GitVersion: kVersion,
 	}
-	return nil
+	if a.createErr != nil {
+		return a.createErr
+	}
+
+	// Like a cluster tool that gets interrupted partway through.
+	return ctx.Err()
 }
 
 func (a *fakeAdmin) LocalRegistryHosting(ctx context.Context, cluster *api.Cluster, registry *api.Registry) (*localregistry.LocalRegistryHostingV1, error) {
@@ -756,13 +1229,39 @@ func (a *fakeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	return nil
 }
 
+func (a *fakeAdmin) Stop(ctx context.Context, config *api.Cluster) error {
+	a.stopped = config.DeepCopy()
+	return nil
+}
+
+func (a *fakeAdmin) Start(ctx context.Context, config *api.Cluster) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	a.started = config.DeepCopy()
+	return nil
+}
+
+func (a *fakeAdmin) LoadImages(ctx context.Context, config *api.Cluster, images []string) error {
+	a.loadedImages = append(a.loadedImages, images...)
+	return nil
+}
+
 type fakeRegistryController struct {
-	lastApply *api.Registry
+	lastApply  *api.Registry
+	lastPush   string
+	lastStop   string
+	lastStart  string
+	lastDelete string
 }
 
 func (c *fakeRegistryController) List(ctx context.Context, options registry.ListOptions) (*api.RegistryList, error) {
 	list := &api.RegistryList{}
-	if c.lastApply != nil {
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if c.lastApply != nil && selector.Matches(labels.Set(c.lastApply.Labels)) {
 		item := c.lastApply.DeepCopy()
 		list.Items = append(list.Items, *item)
 	}
@@ -783,16 +1282,63 @@ func (c *fakeRegistryController) Apply(ctx context.Context, r *api.Registry) (*a
 	return newR, nil
 }
 
+func (c *fakeRegistryController) PushTestImage(ctx context.Context, name string) (string, error) {
+	c.lastPush = name
+	return "ctlptl-verify:latest", nil
+}
+
+func (c *fakeRegistryController) Delete(ctx context.Context, name string) error {
+	c.lastDelete = name
+	return nil
+}
+
+func (c *fakeRegistryController) Stop(ctx context.Context, name string) error {
+	c.lastStop = name
+	return nil
+}
+
+func (c *fakeRegistryController) Start(ctx context.Context, name string) (*api.Registry, error) {
+	c.lastStart = name
+	return &api.Registry{Name: name}, nil
+}
+
 type fakeConfigWriter struct {
 	config *clientcmdapi.Config
 	opts   map[string]string
+
+	// If set, simulates the lock file that kubectl takes on the kubeconfig.
+	lock *fakeKubeconfigLock
 }
 
 func (w fakeConfigWriter) SetContext(name string) error {
+	if w.lock != nil {
+		err := w.lock.acquire()
+		if err != nil {
+			return err
+		}
+		defer w.lock.release()
+	}
 	w.config.CurrentContext = name
 	return nil
 }
 
+// Like kubectl's kubeconfig lock, fails instead of waiting if it's held.
+type fakeKubeconfigLock struct {
+	held atomic.Bool
+}
+
+func (l *fakeKubeconfigLock) acquire() error {
+	if !l.held.CompareAndSwap(false, true) {
+		return fmt.Errorf("open config.lock: file exists")
+	}
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func (l *fakeKubeconfigLock) release() {
+	l.held.Store(false)
+}
+
 func (w fakeConfigWriter) DeleteContext(name string) error {
 	if w.config.CurrentContext == name {
 		w.config.CurrentContext = ""
@@ -805,3 +1351,14 @@ func (w fakeConfigWriter) SetConfig(name, value string) error {
 	w.opts[name] = value
 	return nil
 }
+
+type fakeReporter struct {
+	events []string
+}
+
+func (r *fakeReporter) Start(phase progress.Phase, name string) func(err error) {
+	r.events = append(r.events, fmt.Sprintf("start %s %s", phase, name))
+	return func(err error) {
+		r.events = append(r.events, fmt.Sprintf("end %s %v", phase, err))
+	}
+}
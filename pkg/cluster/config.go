@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"os/exec"
+	"sync"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
@@ -12,6 +13,35 @@ type configWriter interface {
 	SetConfig(name, value string) error
 }
 
+// Serializes kubeconfig writes across all controllers.
+//
+// kubectl locks the kubeconfig while it writes, and fails rather than waits
+// if another writer holds the lock, so clusters applied in parallel
+// have to take turns.
+var kubeconfigMu sync.Mutex
+
+type lockedConfigWriter struct {
+	w configWriter
+}
+
+func (l lockedConfigWriter) SetContext(name string) error {
+	kubeconfigMu.Lock()
+	defer kubeconfigMu.Unlock()
+	return l.w.SetContext(name)
+}
+
+func (l lockedConfigWriter) DeleteContext(name string) error {
+	kubeconfigMu.Lock()
+	defer kubeconfigMu.Unlock()
+	return l.w.DeleteContext(name)
+}
+
+func (l lockedConfigWriter) SetConfig(name, value string) error {
+	kubeconfigMu.Lock()
+	defer kubeconfigMu.Unlock()
+	return l.w.SetConfig(name, value)
+}
+
 type kubeconfigWriter struct {
 	iostreams genericclioptions.IOStreams
 }
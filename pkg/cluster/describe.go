@@ -0,0 +1,274 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/docker"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// Where containerd looks for per-registry config.
+const containerdCertsDir = "/etc/containerd/certs.d"
+
+// How many recent warning events to include in a description.
+const maxDescribeEvents = 10
+
+// A detailed, human-oriented report on a cluster.
+//
+// Collecting a description is best-effort. Parts that can't be read
+// are recorded as errors, so that the rest of the report is still useful.
+type Description struct {
+	Cluster *api.Cluster
+
+	Nodes      []NodeDescription
+	NodesError string
+
+	// The registry that the cluster is configured to use, if any.
+	Registry      *api.Registry
+	RegistryError string
+
+	// Local ports that socat forwards to a remote Docker daemon.
+	// Only applicable on remote Docker.
+	RemoteDocker    bool
+	Forwarders      []int
+	ForwardersError string
+
+	// Recent Warning events from kube-system, oldest first.
+	Events      []corev1.Event
+	EventsError string
+}
+
+type NodeDescription struct {
+	Name             string
+	Ready            string
+	Roles            []string
+	KubeletVersion   string
+	ContainerRuntime string
+	InternalIP       string
+
+	// The Docker container running this node, if any.
+	Container *NodeContainer
+}
+
+type NodeContainer struct {
+	ID       string
+	Image    string
+	State    string
+	Networks []string
+
+	// The registry hosts configured in containerd's certs.d directory.
+	RegistryHosts      []string
+	RegistryHostsError string
+}
+
+// Collect a detailed report on the given cluster.
+func (c *Controller) Describe(ctx context.Context, name string) (*Description, error) {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &Description{Cluster: cluster}
+
+	k8sClient, err := c.client(name)
+	if err != nil {
+		desc.NodesError = err.Error()
+		desc.EventsError = err.Error()
+	} else if cluster.Status.Error != "" {
+		desc.NodesError = cluster.Status.Error
+		desc.EventsError = cluster.Status.Error
+	} else {
+		desc.Nodes, err = c.describeNodes(ctx, k8sClient)
+		if err != nil {
+			desc.NodesError = err.Error()
+		}
+		desc.Events, err = c.warningEvents(ctx, k8sClient)
+		if err != nil {
+			desc.EventsError = err.Error()
+		}
+	}
+
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		// Not every cluster needs Docker.
+		return desc, nil
+	}
+
+	for i, node := range desc.Nodes {
+		desc.Nodes[i].Container = c.describeNodeContainer(ctx, dockerCLI.Client(), node.Name)
+	}
+
+	if cluster.Registry != "" {
+		desc.Registry, err = c.describeRegistry(ctx, cluster.Registry)
+		if err != nil {
+			desc.RegistryError = err.Error()
+		}
+	}
+
+	if !docker.IsLocalHost(dockerCLI.Client().DaemonHost()) {
+		desc.RemoteDocker = true
+		socat, err := c.getSocatController(ctx)
+		if err == nil {
+			desc.Forwarders, err = socat.LocalPortforwarderPorts()
+		}
+		if err != nil {
+			desc.ForwardersError = err.Error()
+		}
+	}
+
+	return desc, nil
+}
+
+func (c *Controller) describeNodes(ctx context.Context, client kubernetes.Interface) ([]NodeDescription, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []NodeDescription{}
+	for _, node := range nodes.Items {
+		ready := "Unknown"
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady {
+				ready = string(cond.Status)
+			}
+		}
+
+		roles := []string{}
+		for label := range node.Labels {
+			role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/")
+			if ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+		sort.Strings(roles)
+
+		internalIP := ""
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP {
+				internalIP = addr.Address
+				break
+			}
+		}
+
+		result = append(result, NodeDescription{
+			Name:             node.Name,
+			Ready:            ready,
+			Roles:            roles,
+			KubeletVersion:   node.Status.NodeInfo.KubeletVersion,
+			ContainerRuntime: node.Status.NodeInfo.ContainerRuntimeVersion,
+			InternalIP:       internalIP,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Container-based clusters (kind, k3d, minikube) name their node containers
+// after the nodes. Returns nil if there's no container for the node.
+func (c *Controller) describeNodeContainer(ctx context.Context, dockerClient dctr.Client, nodeName string) *NodeContainer {
+	inspect, err := dockerClient.ContainerInspect(ctx, nodeName, client.ContainerInspectOptions{})
+	if err != nil || inspect.Container.ID == "" {
+		return nil
+	}
+
+	container := &NodeContainer{ID: inspect.Container.ID}
+	if inspect.Container.Config != nil {
+		container.Image = inspect.Container.Config.Image
+	}
+	if inspect.Container.State != nil {
+		container.State = string(inspect.Container.State.Status)
+	}
+	if inspect.Container.NetworkSettings != nil {
+		for network := range inspect.Container.NetworkSettings.Networks {
+			container.Networks = append(container.Networks, network)
+		}
+		sort.Strings(container.Networks)
+	}
+
+	if inspect.Container.State == nil || !inspect.Container.State.Running {
+		return container
+	}
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	err = c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+		dctr.Binary(dockerClient), "exec", nodeName, "ls", "-1", containerdCertsDir)
+	if err != nil {
+		container.RegistryHostsError = strings.TrimSpace(fmt.Sprintf("%v %s", err, errOut.String()))
+		return container
+	}
+	for _, line := range strings.Split(out.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			container.RegistryHosts = append(container.RegistryHosts, line)
+		}
+	}
+	return container
+}
+
+func (c *Controller) describeRegistry(ctx context.Context, name string) (*api.Registry, error) {
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := registryCtl.List(ctx, registry.ListOptions{FieldSelector: fmt.Sprintf("name=%s", name)})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("registry %s not found", name)
+	}
+	return &list.Items[0], nil
+}
+
+// Reads the most recent Warning events in kube-system.
+func (c *Controller) warningEvents(ctx context.Context, client kubernetes.Interface) ([]corev1.Event, error) {
+	list, err := client.CoreV1().Events("kube-system").List(ctx, metav1.ListOptions{
+		FieldSelector: "type=" + corev1.EventTypeWarning,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := []corev1.Event{}
+	for _, e := range list.Items {
+		if e.Type == corev1.EventTypeWarning {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventTime(events[i]).Before(EventTime(events[j]))
+	})
+	if len(events) > maxDescribeEvents {
+		events = events[len(events)-maxDescribeEvents:]
+	}
+	return events, nil
+}
+
+// The most recent time an event happened.
+func EventTime(e corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
@@ -0,0 +1,375 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/moby/moby/client"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/docker"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// The result of a single diagnostic check.
+type Check struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+
+	// Suggested fix for a warning or failure.
+	Fix string
+}
+
+type DoctorOptions struct {
+	// The products to check. If empty, checks every product
+	// that ctlptl drives with a CLI, and only warns if they're missing.
+	Products []clusterid.Product
+
+	// Local ports that should be free, e.g., for a registry.
+	Ports []int
+}
+
+// An extension of cluster admin that can report the version of its CLI.
+type versionedAdmin interface {
+	version(ctx context.Context) (semver.Version, error)
+}
+
+// Versions of each CLI that ctlptl is known to work with.
+// The minimum is inclusive, the maximum is exclusive.
+type versionRange struct {
+	min semver.Version
+	max semver.Version
+}
+
+var knownGoodVersions = map[clusterid.Product]versionRange{
+	// kind v0.20.0 is the first version with containerd certs.d support.
+	// We only know the node images of versions in kindK8sNodeTable.
+	clusterid.ProductKIND: {
+		min: semver.MustParse("0.20.0"),
+		max: nextMinor(newestKindVersion()),
+	},
+	clusterid.ProductK3D: {
+		min: semver.MustParse("5.0.0"),
+		max: semver.MustParse("6.0.0"),
+	},
+	clusterid.ProductMinikube: {
+		min: v1_26,
+		max: semver.MustParse("2.0.0"),
+	},
+}
+
+func newestKindVersion() semver.Version {
+	newest := semver.Version{}
+	for v := range kindK8sNodeTable {
+		parsed, err := semver.ParseTolerant(v)
+		if err == nil && parsed.GT(newest) {
+			newest = parsed
+		}
+	}
+	return newest
+}
+
+func nextMinor(v semver.Version) semver.Version {
+	return semver.Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// Products that we check by default.
+var doctorProducts = []clusterid.Product{
+	clusterid.ProductKIND,
+	clusterid.ProductK3D,
+	clusterid.ProductMinikube,
+}
+
+// Diagnose the local environment, and suggest fixes for any problems.
+func (c *Controller) Doctor(ctx context.Context, options DoctorOptions) []Check {
+	checks := []Check{}
+
+	dockerCheck, dockerClient := c.checkDocker(ctx)
+	checks = append(checks, dockerCheck)
+
+	products := options.Products
+	required := len(products) > 0
+	if !required {
+		products = doctorProducts
+	}
+	if dockerClient != nil {
+		for _, product := range products {
+			checks = append(checks, c.checkProduct(ctx, product, required))
+		}
+	}
+
+	checks = append(checks, c.checkSocat(dockerClient))
+	checks = append(checks, c.checkKubectl())
+
+	if dockerClient != nil {
+		checks = append(checks, c.checkDefaultNetwork(ctx, dockerClient))
+		checks = append(checks, c.checkPorts(ctx, options.Ports)...)
+	}
+	return checks
+}
+
+// Checks for missing tools that would otherwise make Apply
+// fail halfway through.
+//
+// Only checks that the tools are installed. `ctlptl doctor` runs
+// the slower checks, like CLI versions.
+func (c *Controller) preflight(ctx context.Context, product clusterid.Product) error {
+	// Apply reports products that it can't set up once the machine
+	// has started, because starting the machine may fix them.
+	_, err := c.dockerMachine(ctx)
+	if err != nil {
+		return nil
+	}
+	_, err = c.admin(ctx, product)
+	if err != nil {
+		return nil
+	}
+
+	check, _ := c.checkInstalled(ctx, product, true)
+	if check.Status == CheckFail {
+		msg := check.Message
+		if check.Fix != "" {
+			msg = fmt.Sprintf("%s. %s", msg, check.Fix)
+		}
+		return fmt.Errorf("preflight checks failed:\n  %s", msg)
+	}
+	return nil
+}
+
+func (c *Controller) checkDocker(ctx context.Context) (Check, dctr.Client) {
+	check := Check{Name: "docker"}
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("Docker client: %v", err)
+		check.Fix = "Install Docker: https://docs.docker.com/get-docker/"
+		return check, nil
+	}
+
+	dockerClient := dockerCLI.Client()
+	host := dockerClient.DaemonHost()
+	v, err := dockerClient.ServerVersion(ctx, client.ServerVersionOptions{})
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("Cannot connect to Docker at %s: %v", host, err)
+		check.Fix = "Start Docker, or set DOCKER_HOST to a running Docker engine"
+		return check, nil
+	}
+
+	kind := "remote Docker engine"
+	switch {
+	case docker.IsLocalDockerDesktop(host, c.os):
+		kind = "local Docker Desktop"
+	case docker.IsPodmanHost(host):
+		kind = "local Podman"
+	case docker.IsLocalHost(host):
+		kind = "local Docker engine"
+	}
+
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("%s at %s", kind, host)
+	if v.Version != "" {
+		check.Message = fmt.Sprintf("%s %s at %s", kind, v.Version, host)
+	}
+	return check, dockerClient
+}
+
+// Checks that the product's CLI is installed and in the known-good range.
+//
+// If the product isn't required, a missing CLI is only a warning.
+func (c *Controller) checkProduct(ctx context.Context, product clusterid.Product, required bool) Check {
+	check, admin := c.checkInstalled(ctx, product, required)
+	if admin == nil {
+		return check
+	}
+
+	vAdmin, ok := admin.(versionedAdmin)
+	if !ok {
+		return check
+	}
+
+	v, err := vAdmin.version(ctx)
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("Reading %s version: %v", product, err)
+		return check
+	}
+
+	r, ok := knownGoodVersions[product]
+	switch {
+	case ok && v.LT(r.min):
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("%s v%s is older than v%s", product, v, r.min)
+		check.Fix = fmt.Sprintf("Upgrade %s to v%s or newer", product, r.min)
+	case ok && v.GTE(r.max):
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("%s v%s is newer than the versions ctlptl has been tested with (< v%s)", product, v, r.max)
+		check.Fix = "Upgrade ctlptl, or report any problems at https://github.com/tilt-dev/ctlptl/issues"
+	default:
+		check.Status = CheckPass
+		check.Message = fmt.Sprintf("%s v%s", product, v)
+	}
+	return check
+}
+
+// Checks that the product's CLI is installed.
+//
+// Returns the product's admin if the CLI is installed.
+func (c *Controller) checkInstalled(ctx context.Context, product clusterid.Product, required bool) (Check, Admin) {
+	check := Check{Name: string(product)}
+	missing := CheckWarn
+	if required {
+		missing = CheckFail
+	}
+
+	// The Docker Desktop admin depends on the machine driver, so set it up first.
+	_, err := c.dockerMachine(ctx)
+	if err != nil {
+		check.Status = missing
+		check.Message = err.Error()
+		return check, nil
+	}
+
+	admin, err := c.admin(ctx, product)
+	if err != nil {
+		check.Status = missing
+		check.Message = err.Error()
+		return check, nil
+	}
+
+	err = admin.EnsureInstalled(ctx)
+	if err != nil {
+		check.Status = missing
+		check.Message = fmt.Sprintf("%s not found", product)
+		check.Fix = err.Error()
+		return check, nil
+	}
+
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("%s installed", product)
+	return check, admin
+}
+
+// Remote Docker engines need socat to forward ports to localhost.
+func (c *Controller) checkSocat(dockerClient dctr.Client) Check {
+	check := Check{Name: "socat"}
+	if dockerClient == nil || docker.IsLocalHost(dockerClient.DaemonHost()) {
+		check.Status = CheckPass
+		check.Message = "not needed (Docker is local)"
+		return check
+	}
+
+	_, err := c.lookPath("socat")
+	if err != nil {
+		check.Status = CheckFail
+		check.Message = "socat not found. ctlptl needs socat to forward ports from a remote Docker engine"
+		check.Fix = "Install socat with your package manager, e.g., 'apt install socat' or 'brew install socat'"
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = "socat installed"
+	return check
+}
+
+func (c *Controller) checkKubectl() Check {
+	check := Check{Name: "kubectl"}
+	_, err := c.lookPath("kubectl")
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = "kubectl not found"
+		check.Fix = "Install kubectl: https://kubernetes.io/docs/tasks/tools/"
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = "kubectl installed"
+	return check
+}
+
+// Registries connect to the default network, so that they're
+// reachable from clusters that don't have their own network.
+func (c *Controller) checkDefaultNetwork(ctx context.Context, dockerClient dctr.Client) Check {
+	check := Check{Name: "network"}
+	defaultNetwork := dctr.DefaultNetwork(dockerClient)
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	err := c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+		dctr.Binary(dockerClient), "network", "ls", "--format", "{{.Name}}")
+	if err != nil {
+		check.Status = CheckWarn
+		check.Message = strings.TrimSpace(fmt.Sprintf("Listing networks: %v %s", err, errOut.String()))
+		return check
+	}
+
+	networks := strings.Fields(out.String())
+	if !slices.Contains(networks, defaultNetwork) {
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("Default network %q not found", defaultNetwork)
+		check.Fix = "Restart Docker to recreate its default network"
+		return check
+	}
+	check.Status = CheckPass
+	check.Message = fmt.Sprintf("default network %q", defaultNetwork)
+	return check
+}
+
+func (c *Controller) checkPorts(ctx context.Context, ports []int) []Check {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	// Ports held by our own registries are fine.
+	registryPorts := map[int]string{}
+	registryCtl, err := c.registryController(ctx)
+	if err == nil {
+		list, err := registryCtl.List(ctx, registry.ListOptions{})
+		if err == nil {
+			for _, r := range list.Items {
+				registryPorts[r.Status.HostPort] = r.Name
+			}
+		}
+	}
+
+	checks := []Check{}
+	for _, port := range ports {
+		check := Check{Name: fmt.Sprintf("port %d", port)}
+		if name, ok := registryPorts[port]; ok {
+			check.Status = CheckPass
+			check.Message = fmt.Sprintf("port %d is used by registry %s", port, name)
+			checks = append(checks, check)
+			continue
+		}
+
+		l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			check.Status = CheckFail
+			check.Message = fmt.Sprintf("port %d is in use", port)
+			check.Fix = fmt.Sprintf("Stop the process listening on port %d, or choose a different port", port)
+			checks = append(checks, check)
+			continue
+		}
+		_ = l.Close()
+		check.Status = CheckPass
+		check.Message = fmt.Sprintf("port %d is free", port)
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/exec"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestDoctor(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.started = true
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		if strings.Join(argv[1:], " ") == "network ls --format {{.Name}}" {
+			return "bridge\nhost\nnone\n"
+		}
+		return ""
+	})
+
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	busyPort := l.Addr().(*net.TCPAddr).Port
+
+	checks := f.controller.Doctor(context.Background(), DoctorOptions{
+		Products: []clusterid.Product{clusterid.ProductKIND},
+		Ports:    []int{busyPort},
+	})
+	assert.Equal(t, []Check{
+		{Name: "docker", Status: CheckPass, Message: "local Docker Desktop at unix:///home/nick/.docker/desktop/docker.sock"},
+		{Name: "kind", Status: CheckPass, Message: "kind installed"},
+		{Name: "socat", Status: CheckPass, Message: "not needed (Docker is local)"},
+		{Name: "kubectl", Status: CheckPass, Message: "kubectl installed"},
+		{Name: "network", Status: CheckPass, Message: `default network "bridge"`},
+		{
+			Name:    fmt.Sprintf("port %d", busyPort),
+			Status:  CheckFail,
+			Message: fmt.Sprintf("port %d is in use", busyPort),
+			Fix:     fmt.Sprintf("Stop the process listening on port %d, or choose a different port", busyPort),
+		},
+	}, checks)
+}
+
+func TestDoctorDockerNotStarted(t *testing.T) {
+	f := newFixture(t)
+	f.controller.lookPath = func(file string) (string, error) {
+		return "", fmt.Errorf("not found")
+	}
+
+	checks := f.controller.Doctor(context.Background(), DoctorOptions{})
+	require.Equal(t, 3, len(checks))
+	assert.Equal(t, "docker", checks[0].Name)
+	assert.Equal(t, CheckFail, checks[0].Status)
+	assert.Contains(t, checks[0].Message, "not started")
+	assert.Equal(t, Check{
+		Name:    "kubectl",
+		Status:  CheckWarn,
+		Message: "kubectl not found",
+		Fix:     "Install kubectl: https://kubernetes.io/docs/tasks/tools/",
+	}, checks[2])
+}
+
+func TestDoctorKindVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		status  CheckStatus
+	}{
+		{"0.19.0", CheckWarn},
+		{"0.20.0", CheckPass},
+		{newestKindVersion().String(), CheckPass},
+		{"1.0.0", CheckWarn},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			f := newFixture(t)
+			f.controller.admins[clusterid.ProductKIND] = &fakeVersionedAdmin{
+				fakeAdmin: newFakeAdmin(f.config, f.fakeK8s),
+				v:         semver.MustParse(tc.version),
+			}
+
+			check := f.controller.checkProduct(context.Background(), clusterid.ProductKIND, true)
+			assert.Equal(t, tc.status, check.Status, check.Message)
+		})
+	}
+}
+
+func TestApplyPreflightMissingTool(t *testing.T) {
+	f := newFixture(t)
+	f.controller.admins[clusterid.ProductKIND] = &fakeMissingAdmin{
+		fakeAdmin: newFakeAdmin(f.config, f.fakeK8s),
+	}
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "preflight checks failed:\n  kind not found. kind: command not found")
+	}
+}
+
+func TestPreflightOnlyChecksTools(t *testing.T) {
+	f := newFixture(t)
+
+	// Remote Docker without socat, and an untested kind version,
+	// are only reported by `ctlptl doctor`.
+	f.dockerClient.host = "tcp://192.168.99.100:2376"
+	f.controller.lookPath = func(file string) (string, error) {
+		return "", fmt.Errorf("%s: not found", file)
+	}
+	f.controller.admins[clusterid.ProductKIND] = &fakeVersionedAdmin{
+		fakeAdmin: newFakeAdmin(f.config, f.fakeK8s),
+		v:         semver.MustParse("0.1.0"),
+	}
+
+	err := f.controller.preflight(context.Background(), clusterid.ProductKIND)
+	require.NoError(t, err)
+	assert.Equal(t, "", f.errOut.String())
+}
+
+type fakeMissingAdmin struct {
+	*fakeAdmin
+}
+
+func (a *fakeMissingAdmin) EnsureInstalled(ctx context.Context) error {
+	return fmt.Errorf("kind: command not found")
+}
+
+type fakeVersionedAdmin struct {
+	*fakeAdmin
+	v semver.Version
+}
+
+func (a *fakeVersionedAdmin) version(ctx context.Context) (semver.Version, error) {
+	return a.v, nil
+}
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+type GCOptions struct {
+	// Also delete clusters without a TTL once they're older than this.
+	//
+	// If zero, clusters without a TTL never expire.
+	MaxAge time.Duration
+
+	// Report which clusters have expired, without deleting anything.
+	DryRun bool
+}
+
+type GCResult struct {
+	// The expired clusters. Deleted, unless this was a dry run.
+	Clusters []api.Cluster
+
+	// Registries that were only used by expired clusters.
+	// Deleted, unless this was a dry run.
+	Registries []string
+}
+
+// Deletes clusters that have outlived their TTL, or the max age.
+//
+// Only kind, k3d, and minikube clusters that ctlptl created ever expire.
+//
+// Also deletes registries that no other cluster uses. Clusters that we can't
+// read never expire, and keep their registries alive. If we can't tell which
+// registry such a cluster uses, no registries are deleted. Registry storage
+// volumes are never deleted.
+func (c *Controller) GC(ctx context.Context, options GCOptions) (*GCResult, error) {
+	list, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &GCResult{}
+	inUse := make(map[string]bool)
+	unknownRegistry := false
+	for _, cluster := range list.Items {
+		if clusterExpired(cluster, now, options.MaxAge) &&
+			supportsGC(clusterid.Product(cluster.Product)) && c.isManaged(ctx, cluster.Name) {
+			result.Clusters = append(result.Clusters, cluster)
+		} else if cluster.Registry != "" {
+			inUse[cluster.Registry] = true
+		} else if cluster.Status.Error != "" {
+			unknownRegistry = true
+		}
+	}
+
+	registries := make(map[string]bool)
+	for _, cluster := range result.Clusters {
+		if cluster.Registry != "" && !inUse[cluster.Registry] && !unknownRegistry {
+			registries[cluster.Registry] = true
+		}
+	}
+	for name := range registries {
+		result.Registries = append(result.Registries, name)
+	}
+	sort.Strings(result.Registries)
+
+	if options.DryRun {
+		return result, nil
+	}
+
+	for _, cluster := range result.Clusters {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleting expired cluster %q...\n", cluster.Name)
+		err := c.Delete(ctx, cluster.Name)
+		if err != nil {
+			return nil, fmt.Errorf("deleting cluster %s: %v", cluster.Name, err)
+		}
+	}
+
+	if len(result.Registries) > 0 {
+		registryCtl, err := c.registryController(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range result.Registries {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleting registry %q...\n", name)
+			err := registryCtl.Delete(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("deleting registry %s: %v", name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Checks whether the cluster has outlived its TTL or, if it has no TTL,
+// the max age.
+//
+// Clusters that we can't read never expire.
+func clusterExpired(cluster api.Cluster, now time.Time, maxAge time.Duration) bool {
+	if cluster.Status.Error != "" {
+		return false
+	}
+	if cluster.TTL != "" {
+		expires := cluster.Status.ExpirationTimestamp
+		return expires != nil && now.After(expires.Time)
+	}
+	created := cluster.Status.CreationTimestamp.Time
+	return maxAge > 0 && !created.IsZero() && now.Sub(created) > maxAge
+}
+
+// Checks whether ctlptl can delete clusters of this product, and re-create
+// them later. Deleting a docker-desktop cluster turns off Kubernetes, and
+// remote clusters aren't ours to delete.
+func supportsGC(product clusterid.Product) bool {
+	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube || product == clusterid.ProductK3D
+}
@@ -0,0 +1,203 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/clusterid"
+)
+
+func TestGCExpiredTTL(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setRegistry("kind-registry")
+	f.setSpec("ttl: 1h\n", time.Now().Add(-2*time.Hour))
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	result, err := f.controller.GC(context.Background(), GCOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, "kind-kind", result.Clusters[0].Name)
+	assert.Equal(t, []string{"kind-registry"}, result.Registries)
+	assert.Equal(t, "kind-kind", admin.deleted.Name)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastDelete)
+}
+
+func TestGCSharedRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setRegistry("kind-registry")
+	f.setSpec("ttl: 1h\n", time.Now().Add(-2*time.Hour))
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// The docker-desktop cluster sees the same registry, and never expires,
+	// so it keeps the registry alive.
+	result, err := f.controller.GC(context.Background(), GCOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, "kind-kind", result.Clusters[0].Name)
+	assert.Equal(t, 0, len(result.Registries))
+	assert.Equal(t, "kind-kind", admin.deleted.Name)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestGCUnreachableCluster(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setRegistry("kind-registry")
+	f.setSpec("ttl: 1h\n", time.Now().Add(-2*time.Hour))
+	f.setUnreachable("docker-desktop")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// We can't tell which registry the docker-desktop cluster uses,
+	// so it may be using this one.
+	result, err := f.controller.GC(context.Background(), GCOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, "kind-kind", result.Clusters[0].Name)
+	assert.Equal(t, 0, len(result.Registries))
+	assert.Equal(t, "kind-kind", admin.deleted.Name)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestGCUnexpiredTTL(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setSpec("ttl: 8h\n", time.Now().Add(-2*time.Hour))
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// A TTL takes precedence over the max age.
+	result, err := f.controller.GC(context.Background(), GCOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Nil(t, admin.deleted)
+}
+
+func TestGCDryRun(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setRegistry("kind-registry")
+	f.setSpec("ttl: 1h\n", time.Now().Add(-2*time.Hour))
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	result, err := f.controller.GC(context.Background(), GCOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, []string{"kind-registry"}, result.Registries)
+	assert.Nil(t, admin.deleted)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestGCMaxAge(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.setSpec("", time.Now().Add(-2*time.Hour))
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// Clusters without a TTL never expire by default.
+	result, err := f.controller.GC(context.Background(), GCOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+
+	result, err = f.controller.GC(context.Background(), GCOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+
+	f.setNodeCreated(time.Now().Add(-2 * time.Hour))
+
+	result, err = f.controller.GC(context.Background(), GCOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, "kind-kind", admin.deleted.Name)
+}
+
+func TestGCDockerDesktop(t *testing.T) {
+	f := newFixture(t)
+	f.removeMicroK8s()
+	f.setSpec("ttl: 1h\n", time.Now().Add(-2*time.Hour))
+	f.setNodeCreated(time.Now().Add(-2 * time.Hour))
+	f.dockerClient.started = true
+	admin := f.newFakeAdmin(clusterid.ProductDockerDesktop)
+
+	// Deleting a docker-desktop cluster would turn off Kubernetes.
+	result, err := f.controller.GC(context.Background(), GCOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Nil(t, admin.deleted)
+}
+
+func TestGCUnmanaged(t *testing.T) {
+	f := newFixture(t)
+	f.useKind()
+	f.removeDockerDesktop()
+	f.setNodeCreated(time.Now().Add(-2 * time.Hour))
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// Without a spec in kube-public, ctlptl didn't create the cluster.
+	result, err := f.controller.GC(context.Background(), GCOptions{MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Nil(t, admin.deleted)
+}
+
+// Records a cluster spec, the way ctlptl does on create.
+func (f *fixture) setSpec(spec string, createdAt time.Time) {
+	_, err := f.fakeK8s.CoreV1().ConfigMaps("kube-public").Create(context.Background(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterSpecConfigMap, Namespace: "kube-public"},
+		Data: map[string]string{
+			"cluster.v1alpha1":      spec,
+			clusterSpecCreatedAtKey: createdAt.Format(time.RFC3339),
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(f.t, err)
+}
+
+func (f *fixture) removeDockerDesktop() {
+	delete(f.config.Contexts, "docker-desktop")
+}
+
+func (f *fixture) removeMicroK8s() {
+	delete(f.config.Contexts, "microk8s")
+	f.config.CurrentContext = ""
+	f.controller.config.CurrentContext = ""
+}
+
+// Replaces the microk8s cluster with a kind cluster.
+func (f *fixture) useKind() {
+	f.removeMicroK8s()
+	f.addKindContext("kind-kind")
+	f.dockerClient.started = true
+}
+
+// Backdates the creation of the cluster's node.
+func (f *fixture) setNodeCreated(createdAt time.Time) {
+	_, err := f.fakeK8s.CoreV1().Nodes().Update(context.Background(), &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "node-1",
+			CreationTimestamp: metav1.Time{Time: createdAt},
+		},
+	}, metav1.UpdateOptions{})
+	require.NoError(f.t, err)
+}
+
+// Fails the health check of the named cluster, as if it were stopped.
+func (f *fixture) setUnreachable(name string) {
+	client := fake.NewClientset()
+	client.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	f.controller.clients[name] = client
+}
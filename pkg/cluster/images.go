@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// Copies images from the host's Docker daemon into every node of a cluster,
+// so that pods can use them without pulling.
+//
+// Pulls any images that aren't on the host yet.
+func (c *Controller) LoadImages(ctx context.Context, name string, images []string) (*api.Cluster, error) {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := c.admin(ctx, clusterid.Product(cluster.Product))
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.loadImages(ctx, admin, cluster, images)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+func (c *Controller) loadImages(ctx context.Context, admin Admin, cluster *api.Cluster, images []string) error {
+	loader, ok := admin.(AdminWithImageLoad)
+	if !ok {
+		return fmt.Errorf("cluster %s: loading images into %s clusters is not supported", cluster.Name, cluster.Product)
+	}
+
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		exists, err := dctr.ImageExists(ctx, dockerCLI.Client(), image)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, " 📥 Pulling image %s\n", image)
+		err = dctr.Pull(ctx, dockerCLI, image)
+		if err != nil {
+			return err
+		}
+	}
+
+	return loader.LoadImages(ctx, cluster, images)
+}
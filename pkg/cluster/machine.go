@@ -61,11 +61,13 @@ type d4mClient interface {
 }
 
 type dockerMachine struct {
-	iostreams    genericclioptions.IOStreams
-	dockerClient dctr.Client
-	sleep        sleeper
-	d4m          d4mClient
-	os           string
+	iostreams      genericclioptions.IOStreams
+	dockerClient   dctr.Client
+	sleep          sleeper
+	d4m            d4mClient
+	os             string
+	startTimeout   time.Duration
+	restartTimeout time.Duration
 }
 
 func NewDockerMachine(ctx context.Context, client dctr.Client, iostreams genericclioptions.IOStreams) (*dockerMachine, error) {
@@ -75,11 +77,13 @@ func NewDockerMachine(ctx context.Context, client dctr.Client, iostreams generic
 	}
 
 	return &dockerMachine{
-		dockerClient: client,
-		iostreams:    iostreams,
-		sleep:        time.Sleep,
-		d4m:          d4m,
-		os:           runtime.GOOS,
+		dockerClient:   client,
+		iostreams:      iostreams,
+		sleep:          time.Sleep,
+		d4m:            d4m,
+		os:             runtime.GOOS,
+		startTimeout:   dockerDesktopStartTimeout,
+		restartTimeout: dockerDesktopRestartTimeout,
 	}, nil
 }
 
@@ -112,7 +116,7 @@ func (m *dockerMachine) EnsureExists(ctx context.Context) error {
 		return err
 	}
 
-	dur := 60 * time.Second
+	dur := m.startTimeout
 	_, _ = fmt.Fprintf(m.iostreams.ErrOut, "Waiting %s for Docker Desktop to boot...\n", duration.ShortHumanDuration(dur))
 	err = wait.PollUntilContextTimeout(ctx, time.Second, dur, true, func(ctx context.Context) (bool, error) {
 		_, err := m.dockerClient.ServerVersion(ctx, client.ServerVersionOptions{})
@@ -166,7 +170,7 @@ func (m *dockerMachine) Restart(ctx context.Context, desired, existing *api.Clus
 				return err
 			}
 
-			dur := 120 * time.Second
+			dur := m.restartTimeout
 			_, _ = fmt.Fprintf(m.iostreams.ErrOut,
 				"Applied new Docker Desktop settings. Waiting %s for Docker Desktop to restart...\n",
 				duration.ShortHumanDuration(dur))
@@ -0,0 +1,211 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/visitor"
+)
+
+// The field manager that owns the fields ctlptl applies.
+const manifestFieldManager = "ctlptl"
+
+func defaultDynamicClientLoader(restConfig *rest.Config) (dynamic.Interface, meta.RESTMapper, error) {
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)), nil
+}
+
+func (c *Controller) dynamicClient(name string) (dynamic.Interface, meta.RESTMapper, error) {
+	c.mu.Lock()
+	restConfig, err := clientcmd.NewDefaultClientConfig(
+		c.config, &clientcmd.ConfigOverrides{CurrentContext: name}).ClientConfig()
+	c.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.dynamicClientLoader(restConfig)
+}
+
+// Checks that each manifest is something we can read on every apply.
+//
+// Stdin is already consumed by the time we read the manifests.
+func validateManifests(manifests []string) error {
+	for _, m := range manifests {
+		if m == "-" {
+			return fmt.Errorf("invalid manifest \"-\": manifests must be files, directories, or URLs, not stdin")
+		}
+	}
+	return nil
+}
+
+// Reads the objects in the cluster's manifests.
+//
+// Namespaces and CRDs come first, so that the objects that depend
+// on them can be applied.
+func readManifests(manifests []string) ([]*unstructured.Unstructured, error) {
+	err := validateManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	visitors, err := visitor.FromStrings(manifests, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []*unstructured.Unstructured{}
+	for _, v := range visitors {
+		decoded, err := visitor.DecodeUnstructured(v)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, decoded...)
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return manifestPriority(objs[i]) < manifestPriority(objs[j])
+	})
+	return objs, nil
+}
+
+func manifestPriority(obj *unstructured.Unstructured) int {
+	switch obj.GroupVersionKind().GroupKind().String() {
+	case "Namespace", "CustomResourceDefinition.apiextensions.k8s.io":
+		return 0
+	}
+	return 1
+}
+
+// Server-side applies the cluster's manifests, then waits for
+// the Deployments in them to become available.
+func (c *Controller) applyManifests(ctx context.Context, cluster *api.Cluster) error {
+	objs, err := readManifests(cluster.Manifests)
+	if err != nil {
+		return err
+	}
+
+	client, mapper, err := c.dynamicClient(cluster.Name)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, " 📄 Applying %d objects from manifests\n", len(objs))
+	deployments := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		mapping, err := restMapping(mapper, obj)
+		if err != nil {
+			return err
+		}
+
+		ri := client.Resource(mapping.Resource)
+		var applied *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace("default")
+			}
+			applied, err = ri.Namespace(obj.GetNamespace()).Apply(ctx, obj.GetName(), obj,
+				metav1.ApplyOptions{FieldManager: manifestFieldManager, Force: true})
+		} else {
+			applied, err = ri.Apply(ctx, obj.GetName(), obj,
+				metav1.ApplyOptions{FieldManager: manifestFieldManager, Force: true})
+		}
+		if err != nil {
+			return fmt.Errorf("applying %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if mapping.GroupVersionKind.Group == "apps" && mapping.GroupVersionKind.Kind == "Deployment" {
+			deployments = append(deployments, applied)
+		}
+	}
+
+	for _, d := range deployments {
+		err := c.waitForDeployment(ctx, cluster, d.GetNamespace(), d.GetName())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Maps an object to its resource. If the kind is new (e.g., because we just
+// applied its CRD), refreshes the discovery info and tries again.
+func restMapping(mapper meta.RESTMapper, obj *unstructured.Unstructured) (*meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if r, ok := mapper.(meta.ResettableRESTMapper); ok {
+			r.Reset()
+			mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("applying %s %s: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	return mapping, nil
+}
+
+func (c *Controller) waitForDeployment(ctx context.Context, cluster *api.Cluster, namespace, name string) error {
+	client, err := c.client(cluster.Name)
+	if err != nil {
+		return err
+	}
+
+	isAvailable := func(ctx context.Context) (bool, error) {
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		return deploymentAvailable(d), nil
+	}
+
+	ok, _ := isAvailable(ctx)
+	if ok {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, " ⏳ Waiting %s for deployment %s/%s to become available...\n",
+		duration.ShortHumanDuration(c.waitForDeploymentsTimeout), namespace, name)
+	err = wait.PollUntilContextTimeout(ctx, time.Second, c.waitForDeploymentsTimeout, true, isAvailable)
+	if err != nil {
+		return fmt.Errorf("timed out waiting for deployment %s/%s to become available", namespace, name)
+	}
+	return nil
+}
+
+// Checks that the latest rollout of a deployment has finished,
+// the same way that `kubectl rollout status` does.
+func deploymentAvailable(d *appsv1.Deployment) bool {
+	if d.Status.ObservedGeneration < d.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas >= replicas &&
+		d.Status.Replicas == d.Status.UpdatedReplicas &&
+		d.Status.AvailableReplicas >= replicas
+}
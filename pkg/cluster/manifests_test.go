@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+const manifestsYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: apps
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apps
+`
+
+func TestApplyManifests(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	applied := f.fakeDynamicClient()
+	path := f.writeManifest("apps.yaml", manifestsYAML)
+	f.createDeployment("apps", "web", 2, 2)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:   string(clusterid.ProductKIND),
+		Manifests: []string{path},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, result.Manifests)
+
+	// Namespaces come first.
+	assert.Equal(t, []string{
+		"Namespace /apps",
+		"ConfigMap apps/settings",
+		"Deployment apps/web",
+	}, *applied)
+}
+
+func TestApplyManifestsChanged(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	applied := f.fakeDynamicClient()
+	f.createDeployment("apps", "web", 2, 2)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	kindAdmin.created = nil
+
+	// Adding manifests doesn't re-create the cluster.
+	path := f.writeManifest("apps.yaml", manifestsYAML)
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:   string(clusterid.ProductKIND),
+		Manifests: []string{filepath.Dir(path)},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, []string{filepath.Dir(path)}, result.Manifests)
+	assert.Equal(t, 3, len(*applied))
+
+	// Removing the manifests removes them from the spec.
+	result, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Empty(t, result.Manifests)
+	assert.Equal(t, 3, len(*applied))
+}
+
+func TestApplyManifestsStdin(t *testing.T) {
+	f := newFixture(t)
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:   string(clusterid.ProductKIND),
+		Manifests: []string{"-"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid manifest "-"`)
+	}
+	assert.Nil(t, kindAdmin.created)
+}
+
+func TestApplyManifestsDeploymentNotAvailable(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.fakeDynamicClient()
+	path := f.writeManifest("apps.yaml", manifestsYAML)
+	f.createDeployment("apps", "web", 2, 1)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:   string(clusterid.ProductKIND),
+		Manifests: []string{path},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out waiting for deployment apps/web to become available")
+	}
+}
+
+func TestDeploymentAvailable(t *testing.T) {
+	replicas := int32(2)
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	}
+	assert.True(t, deploymentAvailable(d))
+
+	d.Status.ObservedGeneration = 1
+	assert.False(t, deploymentAvailable(d))
+
+	d.Status.ObservedGeneration = 2
+	d.Status.Replicas = 3
+	assert.False(t, deploymentAvailable(d))
+}
+
+// Records server-side applies as "Kind namespace/name".
+func (f *fixture) fakeDynamicClient() *[]string {
+	applied := []string{}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		obj := &unstructured.Unstructured{}
+		err := yaml.Unmarshal(patch.GetPatch(), &obj.Object)
+		if err != nil {
+			return true, nil, err
+		}
+		applied = append(applied, obj.GetKind()+" "+obj.GetNamespace()+"/"+obj.GetName())
+		return true, obj, nil
+	})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	f.controller.dynamicClientLoader = func(*rest.Config) (dynamic.Interface, meta.RESTMapper, error) {
+		return client, mapper, nil
+	}
+	return &applied
+}
+
+func (f *fixture) writeManifest(name, contents string) string {
+	path := filepath.Join(f.t.TempDir(), name)
+	require.NoError(f.t, os.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+// Creates a deployment with the given number of available replicas.
+func (f *fixture) createDeployment(namespace, name string, replicas, available int32) {
+	_, err := f.fakeK8s.AppsV1().Deployments(namespace).Create(context.Background(),
+		newDeployment(namespace, name, replicas, available), metav1.CreateOptions{})
+	require.NoError(f.t, err)
+}
+
+func newDeployment(namespace, name string, replicas, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          available,
+			UpdatedReplicas:   available,
+			AvailableReplicas: available,
+		},
+	}
+}
@@ -1,29 +1,109 @@
 package cluster
 
 import (
+	"fmt"
+	"strconv"
+
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 )
 
 type ListOptions struct {
 	FieldSelector string
+
+	// Selects clusters by their user-defined labels.
+	LabelSelector string
 }
 
 type clusterFields api.Cluster
 
+// Fields that we know before we talk to the cluster.
+var specFields = map[string]bool{
+	"name":    true,
+	"product": true,
+}
+
+// Fields that we only know after we've queried the cluster.
+var statusFields = map[string]bool{
+	"status.current":           true,
+	"registry":                 true,
+	"status.kubernetesVersion": true,
+	"status.error":             true,
+}
+
 func (cf *clusterFields) Has(field string) bool {
-	return field == "name" || field == "product"
+	return specFields[field] || statusFields[field]
 }
 
 func (cf *clusterFields) Get(field string) string {
-	if field == "name" {
-		return (*api.Cluster)(cf).Name
-	}
-	if field == "product" {
-		return (*api.Cluster)(cf).Product
+	c := (*api.Cluster)(cf)
+	switch field {
+	case "name":
+		return c.Name
+	case "product":
+		return c.Product
+	case "status.current":
+		return strconv.FormatBool(c.Status.Current)
+	case "registry":
+		return c.Registry
+	case "status.kubernetesVersion":
+		return c.Status.KubernetesVersion
+	case "status.error":
+		return c.Status.Error
 	}
 	return ""
 }
 
 var _ fields.Fields = &clusterFields{}
+
+// Selectors parsed from the list options.
+//
+// Populating a cluster is slow, so we split the field selector into
+// the fields we can check up front, and the fields that we can only check
+// once the cluster has been populated.
+type listSelectors struct {
+	spec   fields.Selector
+	status fields.Selector
+	labels labels.Selector
+}
+
+func parseSelectors(options ListOptions) (listSelectors, error) {
+	selector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return listSelectors{}, err
+	}
+
+	spec := []fields.Selector{}
+	status := []fields.Selector{}
+	for _, req := range selector.Requirements() {
+		var term fields.Selector
+		if req.Operator == selection.NotEquals {
+			term = fields.OneTermNotEqualSelector(req.Field, req.Value)
+		} else {
+			term = fields.OneTermEqualSelector(req.Field, req.Value)
+		}
+
+		switch {
+		case specFields[req.Field]:
+			spec = append(spec, term)
+		case statusFields[req.Field]:
+			status = append(status, term)
+		default:
+			return listSelectors{}, fmt.Errorf("field selector %q: unsupported field %q", options.FieldSelector, req.Field)
+		}
+	}
+
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return listSelectors{}, err
+	}
+
+	return listSelectors{
+		spec:   fields.AndSelectors(spec...),
+		status: fields.AndSelectors(status...),
+		labels: labelSelector,
+	}, nil
+}
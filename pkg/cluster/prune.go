@@ -0,0 +1,285 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+// Container labels that tell us which cluster a node belongs to,
+// and how that cluster is named in the kubeconfig.
+var clusterContainerLabels = []struct {
+	product       clusterid.Product
+	label         string
+	contextPrefix string
+}{
+	{clusterid.ProductKIND, kindClusterLabel, "kind-"},
+	{clusterid.ProductK3D, "k3d.cluster", "k3d-"},
+	{clusterid.ProductMinikube, "name.minikube.sigs.k8s.io", ""},
+}
+
+// Resources that failed creates and manual deletes leave behind.
+type Orphans struct {
+	// Clusters that still have containers, but no kubeconfig context.
+	//
+	// Clusters with a running container may be in another kubeconfig,
+	// so only stopped clusters count.
+	Clusters []api.Cluster
+
+	// Kubeconfig contexts of kind and k3d clusters whose containers are gone.
+	Contexts []string
+
+	// Registries that no cluster is connected to.
+	Registries []string
+
+	// Port-forwarding containers on the Docker server that aren't running.
+	Portforwarders []string
+
+	// Ports of local socat forwarders that no cluster or registry listens on.
+	Forwarders []int
+}
+
+func (o *Orphans) Empty() bool {
+	return len(o.Clusters) == 0 && len(o.Contexts) == 0 && len(o.Registries) == 0 &&
+		len(o.Portforwarders) == 0 && len(o.Forwarders) == 0
+}
+
+// The clusters that have node containers, by context name,
+// plus the networks that those containers are connected to.
+type clusterContainers struct {
+	products map[string]clusterid.Product
+	networks map[string]bool
+
+	// Clusters with at least one running node container.
+	running map[string]bool
+}
+
+func listClusterContainers(ctx context.Context, dockerClient dctr.Client) (*clusterContainers, error) {
+	list, err := dockerClient.ContainerList(ctx, client.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &clusterContainers{
+		products: make(map[string]clusterid.Product),
+		networks: make(map[string]bool),
+		running:  make(map[string]bool),
+	}
+	for _, co := range list.Items {
+		for _, l := range clusterContainerLabels {
+			name, ok := co.Labels[l.label]
+			if !ok || name == "" {
+				continue
+			}
+			result.products[l.contextPrefix+name] = l.product
+			if co.State == container.StateRunning {
+				result.running[l.contextPrefix+name] = true
+			}
+			if co.NetworkSettings != nil {
+				for network := range co.NetworkSettings.Networks {
+					result.networks[network] = true
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Finds resources that failed creates and manual deletes left behind.
+//
+// Clusters that we can't reach may still be using a registry or a
+// forwarder, so we only count resources as orphaned when no cluster
+// container is connected to them. If we can't tell which registry
+// such a cluster uses, no registries count as orphaned.
+func (c *Controller) FindOrphans(ctx context.Context) (*Orphans, error) {
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := listClusterContainers(ctx, dockerCLI.Client())
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster containers: %v", err)
+	}
+
+	clusters, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := &Orphans{}
+	config := c.configCopy()
+	names := make([]string, 0, len(containers.products))
+	for name := range containers.products {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := config.Contexts[name]; ok || containers.running[name] {
+			continue
+		}
+		orphans.Clusters = append(orphans.Clusters, api.Cluster{
+			TypeMeta: typeMeta,
+			Name:     name,
+			Product:  containers.products[name].String(),
+		})
+	}
+
+	usedRegistries := make(map[string]bool)
+	unknownRegistry := false
+	for _, cluster := range clusters.Items {
+		if cluster.Registry != "" {
+			usedRegistries[cluster.Registry] = true
+		}
+		if cluster.Status.Error == "" {
+			continue
+		}
+
+		product := clusterid.Product(cluster.Product)
+		_, hasContainers := containers.products[cluster.Name]
+		if !hasContainers && (product == clusterid.ProductKIND || product == clusterid.ProductK3D) {
+			orphans.Contexts = append(orphans.Contexts, cluster.Name)
+		} else if cluster.Registry == "" {
+			// A stopped cluster may still use a registry on the bridge network.
+			unknownRegistry = true
+		}
+	}
+
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registries, err := registryCtl.List(ctx, registry.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usedPorts := make(map[int]bool)
+	for _, r := range registries.Items {
+		if !usedRegistries[r.Name] && !unknownRegistry && !registryOnNetwork(r, containers.networks) {
+			orphans.Registries = append(orphans.Registries, r.Name)
+			continue
+		}
+		usedPorts[r.Status.HostPort] = true
+	}
+
+	socat, err := c.getSocatController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orphans.Portforwarders, err = socat.StoppedRemotePortforwarders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing portforwarders: %v", err)
+	}
+
+	forwarders, err := socat.LocalPortforwarderPorts()
+	if err != nil {
+		return nil, fmt.Errorf("listing portforwarders: %v", err)
+	}
+	for _, cluster := range config.Clusters {
+		usedPorts[apiServerPort(cluster.Server)] = true
+	}
+	for _, port := range forwarders {
+		if !usedPorts[port] {
+			orphans.Forwarders = append(orphans.Forwarders, port)
+		}
+	}
+	return orphans, nil
+}
+
+// Deletes the orphaned resources.
+//
+// Orphaned clusters are deleted with the tool that created them.
+func (c *Controller) Prune(ctx context.Context, orphans *Orphans) error {
+	for i, cluster := range orphans.Clusters {
+		admin, err := c.admin(ctx, clusterid.Product(cluster.Product))
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleting orphaned cluster %q...\n", cluster.Name)
+		err = admin.Delete(ctx, &orphans.Clusters[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range orphans.Contexts {
+		err := c.configWriter.DeleteContext(name)
+		if err != nil {
+			return fmt.Errorf("deleting context %s: %v", name, err)
+		}
+	}
+
+	if len(orphans.Registries) > 0 {
+		registryCtl, err := c.registryController(ctx)
+		if err != nil {
+			return err
+		}
+		for _, name := range orphans.Registries {
+			err := registryCtl.Delete(ctx, name)
+			if err != nil {
+				return fmt.Errorf("deleting registry %s: %v", name, err)
+			}
+		}
+	}
+
+	if len(orphans.Portforwarders) > 0 {
+		dockerCLI, err := c.getDockerCLI(ctx)
+		if err != nil {
+			return err
+		}
+		for _, name := range orphans.Portforwarders {
+			err := dctr.RemoveIfNecessary(ctx, dockerCLI.Client(), name)
+			if err != nil {
+				return fmt.Errorf("deleting portforwarder %s: %v", name, err)
+			}
+		}
+	}
+
+	if len(orphans.Forwarders) > 0 {
+		socat, err := c.getSocatController(ctx)
+		if err != nil {
+			return err
+		}
+		for _, port := range orphans.Forwarders {
+			err := socat.StopLocalPortforwarder(ctx, port)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.reloadConfigs()
+}
+
+func registryOnNetwork(r api.Registry, networks map[string]bool) bool {
+	for _, n := range r.Status.Networks {
+		if n != "bridge" && n != "host" && networks[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses the port out of an API server URL, or 0 if it has none.
+func apiServerPort(server string) int {
+	u, err := url.Parse(server)
+	if err != nil {
+		return 0
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return 0
+	}
+	return port
+}
@@ -0,0 +1,140 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+type PruneAppliedOptions struct {
+	// The clusters and registries that were applied, by name.
+	// These are never pruned.
+	Clusters   []string
+	Registries []string
+
+	// Only prune clusters and registries whose labels match.
+	LabelSelector string
+
+	// Report what would be pruned, without deleting anything.
+	DryRun bool
+}
+
+type PruneAppliedResult struct {
+	// Managed clusters that weren't applied. Deleted, unless this was a dry run.
+	Clusters []api.Cluster
+
+	// Managed registries that weren't applied, and that no remaining
+	// cluster uses. Deleted, unless this was a dry run.
+	Registries []api.Registry
+}
+
+// Deletes the clusters and registries that ctlptl manages,
+// but that weren't in the applied set.
+//
+// A cluster is managed if ctlptl wrote its spec to kube-public. Clusters
+// that we can't read are never pruned, and keep their registries alive.
+// If we can't tell which registry such a cluster uses, no registries are
+// pruned. A registry is managed if ctlptl created its container. The
+// registry of a pruned cluster is pruned too, unless another cluster uses it.
+func (c *Controller) PruneApplied(ctx context.Context, options PruneAppliedOptions) (*PruneAppliedResult, error) {
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prune selector %q: %v", options.LabelSelector, err)
+	}
+
+	list, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	keepClusters := make(map[string]bool)
+	for _, name := range options.Clusters {
+		keepClusters[name] = true
+	}
+	keepRegistries := make(map[string]bool)
+	for _, name := range options.Registries {
+		keepRegistries[name] = true
+	}
+
+	result := &PruneAppliedResult{}
+	unknownRegistry := false
+	for _, cluster := range list.Items {
+		if !keepClusters[cluster.Name] && cluster.Status.Error == "" &&
+			selector.Matches(labels.Set(cluster.Labels)) && c.isManaged(ctx, cluster.Name) {
+			result.Clusters = append(result.Clusters, cluster)
+		} else if cluster.Registry != "" {
+			keepRegistries[cluster.Registry] = true
+		} else if cluster.Status.Error != "" {
+			unknownRegistry = true
+		}
+	}
+
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registries, err := registryCtl.List(ctx, registry.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	pruned := make(map[string]bool)
+	for _, r := range registries.Items {
+		if !keepRegistries[r.Name] && !unknownRegistry {
+			result.Registries = append(result.Registries, r)
+			pruned[r.Name] = true
+		}
+	}
+
+	// A registry that ctlptl created for a cluster doesn't have the cluster's
+	// labels, so also prune the registries of pruned clusters.
+	for _, cluster := range result.Clusters {
+		name := cluster.Registry
+		if name == "" || pruned[name] || keepRegistries[name] || unknownRegistry {
+			continue
+		}
+		list, err := registryCtl.List(ctx, registry.ListOptions{FieldSelector: fmt.Sprintf("name=%s", name)})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list.Items {
+			result.Registries = append(result.Registries, r)
+			pruned[r.Name] = true
+		}
+	}
+
+	if options.DryRun {
+		return result, nil
+	}
+
+	for _, cluster := range result.Clusters {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Pruning cluster %q...\n", cluster.Name)
+		err := c.Delete(ctx, cluster.Name)
+		if err != nil {
+			return nil, fmt.Errorf("deleting cluster %s: %v", cluster.Name, err)
+		}
+	}
+	for _, r := range result.Registries {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Pruning registry %q...\n", r.Name)
+		err := registryCtl.Delete(ctx, r.Name)
+		if err != nil {
+			return nil, fmt.Errorf("deleting registry %s: %v", r.Name, err)
+		}
+	}
+	return result, nil
+}
+
+// Checks whether ctlptl wrote the cluster's spec, which means
+// that ctlptl created or applied the cluster.
+func (c *Controller) isManaged(ctx context.Context, name string) bool {
+	client, err := c.client(name)
+	if err != nil {
+		return false
+	}
+	_, err = client.CoreV1().ConfigMaps("kube-public").Get(ctx, clusterSpecConfigMap, metav1.GetOptions{})
+	return err == nil
+}
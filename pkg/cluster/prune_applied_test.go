@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+)
+
+func TestPruneApplied(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	f.registryCtl.lastApply.Labels = map[string]string{"env": "ci"}
+	f.setSpec("labels:\n  env: ci\n", time.Now())
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "env=ci"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, "microk8s", result.Clusters[0].Name)
+	require.Equal(t, 1, len(result.Registries))
+	assert.Equal(t, "kind-registry", result.Registries[0].Name)
+	assert.Equal(t, "microk8s", admin.deleted.Name)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastDelete)
+}
+
+func TestPruneAppliedClusterRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	f.setSpec("labels:\n  env: ci\n", time.Now())
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	// The registry doesn't have the cluster's labels,
+	// but nothing else uses it once the cluster is gone.
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "env=ci"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	require.Equal(t, 1, len(result.Registries))
+	assert.Equal(t, "kind-registry", result.Registries[0].Name)
+	assert.Equal(t, "microk8s", admin.deleted.Name)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastDelete)
+}
+
+func TestPruneAppliedKeepsApplied(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	f.setSpec("labels:\n  env: ci\n", time.Now())
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	// The registry isn't in the applied set, but the applied cluster uses it.
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{
+		Clusters:      []string{"microk8s"},
+		LabelSelector: "env=ci",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Equal(t, 0, len(result.Registries))
+	assert.Nil(t, admin.deleted)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestPruneAppliedUnreachableCluster(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	f.registryCtl.lastApply.Labels = map[string]string{"env": "ci"}
+	f.setSpec("labels:\n  env: ci\n", time.Now())
+	f.setUnreachable("docker-desktop")
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	// We can't tell which registry the docker-desktop cluster uses.
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "env=ci"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	assert.Equal(t, 0, len(result.Registries))
+	assert.Equal(t, "microk8s", admin.deleted.Name)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestPruneAppliedSelector(t *testing.T) {
+	f := newFixture(t)
+	f.setSpec("labels:\n  env: dev\n", time.Now())
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "env=ci"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Nil(t, admin.deleted)
+}
+
+func TestPruneAppliedUnmanaged(t *testing.T) {
+	f := newFixture(t)
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	// Without a spec in kube-public, ctlptl didn't create the cluster.
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "!env"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result.Clusters))
+	assert.Nil(t, admin.deleted)
+}
+
+func TestPruneAppliedDryRun(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	f.setSpec("labels:\n  env: ci\n", time.Now())
+	f.removeDockerDesktop()
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	result, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{
+		LabelSelector: "env=ci",
+		DryRun:        true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Clusters))
+	require.Equal(t, 1, len(result.Registries))
+	assert.Nil(t, admin.deleted)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+func TestPruneAppliedInvalidSelector(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.PruneApplied(context.Background(), PruneAppliedOptions{LabelSelector: "env in ("})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid prune selector "env in ("`)
+	}
+}
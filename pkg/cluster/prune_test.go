@@ -0,0 +1,120 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestFindOrphans(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.started = true
+	f.addKindContext("kind-kind")
+	f.addKindContext("kind-dead")
+	f.setUnreachable("kind-dead")
+	other := kindClusterNode("other", "kind")
+	other.State = container.StateRunning
+	f.dockerClient.containers = []container.Summary{
+		kindClusterNode("kind", "kind"),
+		kindClusterNode("old", "kind"),
+
+		// A running cluster may be in another kubeconfig.
+		other,
+		{
+			Names: []string{"/ctlptl-portforward-service"},
+			State: container.StateExited,
+		},
+	}
+	f.registryCtl.lastApply = &api.Registry{
+		Name:   "old-registry",
+		Status: api.RegistryStatus{HostPort: 5005, Networks: []string{"bridge"}},
+	}
+
+	orphans, err := f.controller.FindOrphans(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(orphans.Clusters))
+	assert.Equal(t, "kind-old", orphans.Clusters[0].Name)
+	assert.Equal(t, "kind", orphans.Clusters[0].Product)
+	assert.Equal(t, []string{"kind-dead"}, orphans.Contexts)
+	assert.Equal(t, []string{"old-registry"}, orphans.Registries)
+	assert.Equal(t, []string{"ctlptl-portforward-service"}, orphans.Portforwarders)
+}
+
+func TestFindOrphansRegistryOnClusterNetwork(t *testing.T) {
+	f := newFixture(t)
+	f.addKindContext("kind-kind")
+	f.dockerClient.containers = []container.Summary{kindClusterNode("kind", "kind")}
+
+	// The kind cluster may not be running, but its containers
+	// are still connected to the registry.
+	f.registryCtl.lastApply = &api.Registry{
+		Name:   "kind-registry",
+		Status: api.RegistryStatus{HostPort: 5005, Networks: []string{"bridge", "kind"}},
+	}
+
+	orphans, err := f.controller.FindOrphans(context.Background())
+	require.NoError(t, err)
+	assert.True(t, orphans.Empty())
+}
+
+func TestFindOrphansUnreachableCluster(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.started = true
+	f.setUnreachable("docker-desktop")
+
+	// The stopped docker-desktop cluster may still use the registry.
+	f.registryCtl.lastApply = &api.Registry{
+		Name:   "kind-registry",
+		Status: api.RegistryStatus{HostPort: 5005, Networks: []string{"bridge"}},
+	}
+
+	orphans, err := f.controller.FindOrphans(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(orphans.Registries))
+}
+
+func TestPrune(t *testing.T) {
+	f := newFixture(t)
+	f.addKindContext("kind-dead")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	err := f.controller.Prune(context.Background(), &Orphans{
+		Clusters:   []api.Cluster{{Name: "kind-old", Product: "kind"}},
+		Contexts:   []string{"kind-dead"},
+		Registries: []string{"old-registry"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kind-old", admin.deleted.Name)
+	assert.Equal(t, "old-registry", f.registryCtl.lastDelete)
+	_, ok := f.config.Contexts["kind-dead"]
+	assert.False(t, ok)
+}
+
+func TestAPIServerPort(t *testing.T) {
+	assert.Equal(t, 6443, apiServerPort("https://127.0.0.1:6443"))
+	assert.Equal(t, 0, apiServerPort("http://microk8s.localhost/"))
+}
+
+func (f *fixture) addKindContext(name string) {
+	f.config.Contexts[name] = &clientcmdapi.Context{Cluster: name}
+	f.config.Clusters[name] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+}
+
+func kindClusterNode(cluster, networkName string) container.Summary {
+	return container.Summary{
+		Names:  []string{"/" + cluster + "-control-plane"},
+		Labels: map[string]string{kindClusterLabel: cluster},
+		NetworkSettings: &container.NetworkSettingsSummary{
+			Networks: map[string]*network.EndpointSettings{networkName: {}},
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
+)
+
+// How long we give ourselves to delete a cluster that failed to create.
+const rollbackTimeout = 2 * time.Minute
+
+// Docker Desktop clusters can't be deleted without turning off Kubernetes,
+// so we only roll back clusters that live in their own containers.
+func supportsRollback(product clusterid.Product) bool {
+	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube || product == clusterid.ProductK3D
+}
+
+// Checks whether any containers belong to the named cluster. If we can't
+// tell, assumes that they do.
+func (c *Controller) hasClusterContainers(ctx context.Context, name string) bool {
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return true
+	}
+	containers, err := listClusterContainers(ctx, dockerCLI.Client())
+	if err != nil {
+		return true
+	}
+	_, ok := containers.products[name]
+	return ok
+}
+
+// Deletes a cluster that failed to create, or that the user interrupted,
+// so that it doesn't linger. Also deletes its registry, if we created
+// the registry for it.
+//
+// Returns the error that caused the rollback.
+func (c *Controller) rollbackCreate(ctx context.Context, admin Admin, cluster *api.Cluster, deleteRegistry bool, cause error) error {
+	c.mu.Lock()
+	keepOnFailure := c.keepOnFailure
+	c.mu.Unlock()
+	if keepOnFailure || !supportsRollback(clusterid.Product(cluster.Product)) {
+		return cause
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+		"Creating cluster %s failed. Deleting it (use --keep-on-failure to keep it)...\n", cluster.Name)
+
+	// The context may have been cancelled by Ctrl-C, but we still want to clean up.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	done := c.startPhase(progress.PhaseRollback, cluster.Name)
+	err := c.deleteHalfCreated(ctx, admin, cluster, deleteRegistry)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("%v (%v)", cause, err)
+	}
+	return cause
+}
+
+func (c *Controller) deleteHalfCreated(ctx context.Context, admin Admin, cluster *api.Cluster, deleteRegistry bool) error {
+	err := admin.Delete(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("deleting cluster %s also failed: %v", cluster.Name, err)
+	}
+
+	err = c.reloadConfigs()
+	if err != nil {
+		return fmt.Errorf("deleting cluster %s also failed: %v", cluster.Name, err)
+	}
+	if _, ok := c.configCopy().Contexts[cluster.Name]; ok {
+		err = c.configWriter.DeleteContext(cluster.Name)
+		if err != nil {
+			return fmt.Errorf("deleting context %s also failed: %v", cluster.Name, err)
+		}
+	}
+
+	if deleteRegistry && cluster.Registry != "" {
+		regCtl, err := c.registryController(ctx)
+		if err == nil {
+			err = regCtl.Delete(ctx, cluster.Registry)
+		}
+		if err != nil {
+			return fmt.Errorf("deleting registry %s also failed: %v", cluster.Registry, err)
+		}
+	}
+	return nil
+}
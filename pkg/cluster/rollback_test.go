@@ -0,0 +1,99 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestClusterApplyRollback(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	kindAdmin.createErr = fmt.Errorf("kind create failed")
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "ctlptl-registry",
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "kind create failed", err.Error())
+	}
+	require.NotNil(t, kindAdmin.deleted)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+	assert.NotContains(t, f.config.Contexts, "kind-kind")
+	assert.Equal(t, "ctlptl-registry", f.registryCtl.lastDelete)
+	assert.Contains(t, f.errOut.String(), "Creating cluster kind-kind failed. Deleting it")
+}
+
+func TestClusterApplyRollbackInterrupted(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.controller.Apply(ctx, &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, kindAdmin.deleted)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+}
+
+func TestClusterApplyKeepOnFailure(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	kindAdmin.createErr = fmt.Errorf("kind create failed")
+	f.controller.SetKeepOnFailure(true)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "ctlptl-registry",
+	})
+	assert.Error(t, err)
+	assert.Nil(t, kindAdmin.deleted)
+	assert.Contains(t, f.config.Contexts, "kind-kind")
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
+
+// If the cluster's containers were there before we tried to create it,
+// the cluster isn't ours to delete.
+func TestClusterApplyNoRollbackOfExistingContainers(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.dockerClient.containers = []container.Summary{kindClusterNode("kind", "kind")}
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	kindAdmin.createErr = fmt.Errorf("node(s) already exist for a cluster with the name \"kind\"")
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	assert.Error(t, err)
+	assert.Nil(t, kindAdmin.deleted)
+}
+
+func TestClusterApplyNoRollbackOfExistingRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.registryCtl.lastApply = &api.Registry{Name: "ctlptl-registry"}
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	kindAdmin.createErr = fmt.Errorf("kind create failed")
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "ctlptl-registry",
+	})
+	assert.Error(t, err)
+	require.NotNil(t, kindAdmin.deleted)
+	assert.Equal(t, "", f.registryCtl.lastDelete)
+}
@@ -0,0 +1,454 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// Snapshots are stored as one local image per node, with labels
+// that record which snapshot the image belongs to.
+const (
+	snapshotLabel        = "dev.tilt.ctlptl.snapshot"
+	snapshotClusterLabel = "dev.tilt.ctlptl.snapshot.cluster"
+	snapshotNodeLabel    = "dev.tilt.ctlptl.snapshot.node"
+	snapshotRoleLabel    = "dev.tilt.ctlptl.snapshot.role"
+	snapshotSpecLabel    = "dev.tilt.ctlptl.snapshot.spec"
+
+	snapshotRepo = "ctlptl-snapshot"
+
+	kindClusterLabel = "io.x-k8s.kind.cluster"
+	kindRoleLabel    = "io.x-k8s.kind.role"
+)
+
+// How long we give ourselves to clean up and restart the cluster
+// after taking a snapshot.
+const snapshotRestartTimeout = 5 * time.Minute
+
+var snapshotGroupResource = schema.GroupResource{Group: "ctlptl.dev", Resource: "snapshots"}
+
+var snapshotTypeMeta = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "Snapshot"}
+var snapshotListTypeMeta = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "SnapshotList"}
+
+// Snapshot names are used in image references, so must be valid
+// image path components.
+var snapshotNameRegexp = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// Saves the nodes of a cluster as local images, so that the cluster
+// can be re-created from them later.
+//
+// Stops the cluster while we copy the nodes, then starts it again.
+func (c *Controller) CreateSnapshot(ctx context.Context, clusterName, name string) (*api.Snapshot, error) {
+	if !snapshotNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q: must be lowercase letters, digits, and separators (., _, -)", name)
+	}
+
+	cluster, err := c.Get(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if clusterid.Product(cluster.Product) != clusterid.ProductKIND {
+		return nil, fmt.Errorf("cluster %s: snapshots are only supported for kind clusters", cluster.Name)
+	}
+
+	_, err = c.GetSnapshot(ctx, name)
+	if err == nil {
+		return nil, fmt.Errorf("snapshot %s already exists", name)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := kindSnapshotNodes(ctx, dockerCLI.Client(), cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := cluster.DeepCopy()
+	spec.TypeMeta = TypeMeta()
+	spec.Status = api.ClusterStatus{}
+	specYAML, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := c.stopAdmin(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copying the nodes while they're running could catch etcd mid-write.
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Stopping cluster %q to take snapshot %q...\n", cluster.Name, name)
+	err = admin.Stop(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &api.Snapshot{
+		TypeMeta:          snapshotTypeMeta,
+		Name:              name,
+		Cluster:           cluster.Name,
+		Spec:              spec,
+		CreationTimestamp: metav1.Time{Time: time.Now()},
+	}
+	var snapshotErr error
+	for _, node := range nodes {
+		node.Image, err = c.snapshotNode(ctx, dockerCLI.Client(), node, snapshot, string(specYAML))
+		if err != nil {
+			snapshotErr = errors.Wrapf(err, "snapshotting node %s", node.Name)
+			break
+		}
+		snapshot.Nodes = append(snapshot.Nodes, node)
+	}
+
+	// The context may have been cancelled by Ctrl-C, but we still want to
+	// clean up and leave the cluster running.
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), snapshotRestartTimeout)
+	defer cancel()
+
+	// The images of a partial snapshot would block retries with the same name.
+	if snapshotErr != nil && len(snapshot.Nodes) > 0 {
+		err = c.removeSnapshotImages(cleanupCtx, dockerCLI.Client(), snapshot.Nodes)
+		if err != nil {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Warning: removing images of snapshot %q: %v\n", name, err)
+		}
+	}
+
+	// Restart the cluster even if the snapshot failed.
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Starting cluster %q...\n", cluster.Name)
+	err = admin.Start(cleanupCtx, cluster)
+	if err == nil {
+		err = c.waitForHealthCheckAfterCreate(cleanupCtx, cluster)
+	}
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Commits a stopped node container to an image.
+//
+// Kind mounts a volume at /var, where etcd and containerd keep their data.
+// Volumes aren't included in commits, so we copy /var into a new layer
+// on top of the committed image.
+func (c *Controller) snapshotNode(ctx context.Context, dockerClient dctr.Client, node api.SnapshotNode, snapshot *api.Snapshot, specYAML string) (string, error) {
+	committed, err := dockerClient.ContainerCommit(ctx, node.Name, client.ContainerCommitOptions{
+		Comment: fmt.Sprintf("ctlptl snapshot %s", snapshot.Name),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "ctlptl-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	bin := dctr.Binary(dockerClient)
+	varTar, err := os.Create(filepath.Join(dir, "var.tar"))
+	if err != nil {
+		return "", err
+	}
+	err = c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: varTar, ErrOut: c.iostreams.ErrOut},
+		bin, "cp", fmt.Sprintf("%s:/var", node.Name), "-")
+	closeErr := varTar.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "copying /var")
+	}
+	if closeErr != nil {
+		return "", closeErr
+	}
+
+	dockerfile := fmt.Sprintf("FROM %s\nADD var.tar /\n", committed.ID)
+	err = os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	ref := fmt.Sprintf("%s/%s:%s", snapshotRepo, snapshot.Name, node.Name)
+	labels := []string{
+		fmt.Sprintf("%s=%s", snapshotLabel, snapshot.Name),
+		fmt.Sprintf("%s=%s", snapshotClusterLabel, snapshot.Cluster),
+		fmt.Sprintf("%s=%s", snapshotNodeLabel, node.Name),
+		fmt.Sprintf("%s=%s", snapshotRoleLabel, node.Role),
+		fmt.Sprintf("%s=%s", snapshotSpecLabel, specYAML),
+	}
+	args := []string{"build", "-t", ref}
+	for _, label := range labels {
+		args = append(args, "--label", label)
+	}
+	args = append(args, dir)
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "   Saving node %s as %s\n", node.Name, ref)
+	err = c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: c.iostreams.ErrOut, ErrOut: c.iostreams.ErrOut},
+		bin, args...)
+	if err != nil {
+		return "", errors.Wrap(err, "building snapshot image")
+	}
+	return ref, nil
+}
+
+// Removes the images of the given snapshot nodes.
+func (c *Controller) removeSnapshotImages(ctx context.Context, dockerClient dctr.Client, nodes []api.SnapshotNode) error {
+	args := []string{"rmi"}
+	for _, node := range nodes {
+		args = append(args, node.Image)
+	}
+	return c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: io.Discard, ErrOut: c.iostreams.ErrOut},
+		dctr.Binary(dockerClient), args...)
+}
+
+// Finds the node containers of a kind cluster, in the order that
+// kind creates them.
+//
+// Skips the external load balancer, which kind re-creates from its own image.
+func kindSnapshotNodes(ctx context.Context, dockerClient dctr.Client, cluster *api.Cluster) ([]api.SnapshotNode, error) {
+	kindName := strings.TrimPrefix(cluster.Name, "kind-")
+	filters := client.Filters{}
+	filters.Add("label", fmt.Sprintf("%s=%s", kindClusterLabel, kindName))
+	list, err := dockerClient.ContainerList(ctx, client.ContainerListOptions{
+		Filters: filters,
+		All:     true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing kind nodes")
+	}
+
+	nodes := []api.SnapshotNode{}
+	for _, container := range list.Items {
+		role := container.Labels[kindRoleLabel]
+		if role == "external-load-balancer" || len(container.Names) == 0 {
+			continue
+		}
+		nodes = append(nodes, api.SnapshotNode{
+			Name: strings.TrimPrefix(container.Names[0], "/"),
+			Role: role,
+		})
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no node containers found for kind cluster %s", kindName)
+	}
+	sortSnapshotNodes(nodes)
+	return nodes, nil
+}
+
+// Sorts control plane nodes before workers. Within a role, sorts
+// kind-worker2 before kind-worker10.
+func sortSnapshotNodes(nodes []api.SnapshotNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		iControl := nodes[i].Role == string(v1alpha4.ControlPlaneRole)
+		jControl := nodes[j].Role == string(v1alpha4.ControlPlaneRole)
+		if iControl != jControl {
+			return iControl
+		}
+		if len(nodes[i].Name) != len(nodes[j].Name) {
+			return len(nodes[i].Name) < len(nodes[j].Name)
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+}
+
+func (c *Controller) GetSnapshot(ctx context.Context, name string) (*api.Snapshot, error) {
+	list, err := c.listSnapshots(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, apierrors.NewNotFound(snapshotGroupResource, name)
+	}
+	item := list.Items[0]
+	return &item, nil
+}
+
+func (c *Controller) ListSnapshots(ctx context.Context) (*api.SnapshotList, error) {
+	return c.listSnapshots(ctx, "")
+}
+
+// Reads snapshots from the labels of local images.
+//
+// If name is non-empty, only reads that snapshot.
+func (c *Controller) listSnapshots(ctx context.Context, name string) (*api.SnapshotList, error) {
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := client.Filters{}
+	if name != "" {
+		filters.Add("label", fmt.Sprintf("%s=%s", snapshotLabel, name))
+	} else {
+		filters.Add("label", snapshotLabel)
+	}
+	images, err := dockerCLI.Client().ImageList(ctx, client.ImageListOptions{Filters: filters})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing snapshot images")
+	}
+
+	byName := make(map[string]*api.Snapshot)
+	for _, image := range images.Items {
+		snapshotName := image.Labels[snapshotLabel]
+		snapshot, ok := byName[snapshotName]
+		if !ok {
+			snapshot = &api.Snapshot{
+				TypeMeta: snapshotTypeMeta,
+				Name:     snapshotName,
+				Cluster:  image.Labels[snapshotClusterLabel],
+			}
+			spec := &api.Cluster{}
+			err := yaml.Unmarshal([]byte(image.Labels[snapshotSpecLabel]), spec)
+			if err == nil {
+				snapshot.Spec = spec
+			}
+			byName[snapshotName] = snapshot
+		}
+
+		// A snapshot was taken when its first node was saved.
+		created := time.Unix(image.Created, 0)
+		if snapshot.CreationTimestamp.IsZero() || created.Before(snapshot.CreationTimestamp.Time) {
+			snapshot.CreationTimestamp = metav1.Time{Time: created}
+		}
+
+		ref := image.ID
+		if len(image.RepoTags) > 0 {
+			ref = image.RepoTags[0]
+		}
+		snapshot.Nodes = append(snapshot.Nodes, api.SnapshotNode{
+			Name:  image.Labels[snapshotNodeLabel],
+			Role:  image.Labels[snapshotRoleLabel],
+			Image: ref,
+			Size:  image.Size,
+		})
+	}
+
+	result := []api.Snapshot{}
+	for _, snapshot := range byName {
+		sortSnapshotNodes(snapshot.Nodes)
+		result = append(result, *snapshot)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return &api.SnapshotList{
+		TypeMeta: snapshotListTypeMeta,
+		Items:    result,
+	}, nil
+}
+
+// Re-creates a cluster from a snapshot.
+//
+// The cluster must not exist. Creates the cluster with the snapshot's spec,
+// with each node started from its snapshot image, and re-connects the registry.
+func (c *Controller) RestoreSnapshot(ctx context.Context, name string) (*api.Cluster, error) {
+	snapshot, err := c.GetSnapshot(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Spec == nil {
+		return nil, fmt.Errorf("snapshot %s: missing cluster spec", name)
+	}
+	spec := snapshot.Spec
+	if clusterid.Product(spec.Product) != clusterid.ProductKIND {
+		return nil, fmt.Errorf("snapshot %s: snapshots are only supported for kind clusters", name)
+	}
+
+	_, err = c.Get(ctx, spec.Name)
+	if err == nil {
+		return nil, fmt.Errorf("cluster %s already exists. Delete it before restoring snapshot %s", spec.Name, name)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	desired := spec.DeepCopy()
+
+	// The node images determine the Kubernetes version.
+	desired.KubernetesVersion = ""
+	desired.KindV1Alpha4Cluster, err = snapshotKindConfig(spec.KindV1Alpha4Cluster, snapshot.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %v", name, err)
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Restoring cluster %q from snapshot %q...\n", spec.Name, name)
+	_, err = c.Apply(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the original spec, so that applying it
+	// later doesn't re-create the cluster.
+	err = c.writeClusterSpec(ctx, spec, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, spec.Name)
+}
+
+// Copies the kind config, and starts each node from its snapshot image.
+func snapshotKindConfig(config *v1alpha4.Cluster, nodes []api.SnapshotNode) (*v1alpha4.Cluster, error) {
+	result := &v1alpha4.Cluster{}
+	if config != nil {
+		result = config.DeepCopy()
+	}
+	if len(result.Nodes) == 0 {
+		for _, node := range nodes {
+			result.Nodes = append(result.Nodes, v1alpha4.Node{Role: v1alpha4.NodeRole(node.Role)})
+		}
+	}
+
+	byRole := make(map[v1alpha4.NodeRole][]api.SnapshotNode)
+	for _, node := range nodes {
+		role := v1alpha4.NodeRole(node.Role)
+		byRole[role] = append(byRole[role], node)
+	}
+
+	for i, node := range result.Nodes {
+		role := node.Role
+		if role == "" {
+			role = v1alpha4.ControlPlaneRole
+		}
+		saved := byRole[role]
+		if len(saved) == 0 {
+			return nil, fmt.Errorf("no saved node for %s node %d", role, i)
+		}
+		result.Nodes[i].Image = saved[0].Image
+		byRole[role] = saved[1:]
+	}
+
+	for role, saved := range byRole {
+		if len(saved) > 0 {
+			return nil, fmt.Errorf("%d saved %s nodes aren't in the kind config", len(saved), role)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,240 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/internal/exec"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestCreateSnapshot(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	f.dockerClient.containers = []container.Summary{
+		kindNode("kind-worker10", "worker"),
+		kindNode("kind-worker2", "worker"),
+		kindNode("kind-control-plane", "control-plane"),
+		kindNode("kind-external-load-balancer", "external-load-balancer"),
+	}
+	execs := [][]string{}
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		execs = append(execs, argv)
+		return ""
+	})
+
+	snapshot, err := f.controller.CreateSnapshot(context.Background(), "kind-kind", "base")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", admin.stopped.Name)
+	assert.Equal(t, "kind-kind", admin.started.Name)
+	assert.Equal(t, []string{"kind-control-plane", "kind-worker2", "kind-worker10"}, f.dockerClient.commits)
+
+	assert.Equal(t, "base", snapshot.Name)
+	assert.Equal(t, "kind-kind", snapshot.Cluster)
+	assert.Equal(t, "kind", snapshot.Spec.Product)
+	assert.Equal(t, []api.SnapshotNode{
+		{Name: "kind-control-plane", Role: "control-plane", Image: "ctlptl-snapshot/base:kind-control-plane"},
+		{Name: "kind-worker2", Role: "worker", Image: "ctlptl-snapshot/base:kind-worker2"},
+		{Name: "kind-worker10", Role: "worker", Image: "ctlptl-snapshot/base:kind-worker10"},
+	}, snapshot.Nodes)
+
+	require.Len(t, execs, 6)
+	assert.Equal(t, []string{"docker", "cp", "kind-control-plane:/var", "-"}, execs[0])
+	build := execs[1]
+	assert.Equal(t, []string{"docker", "build", "-t", "ctlptl-snapshot/base:kind-control-plane"}, build[:4])
+	assert.Contains(t, build, "dev.tilt.ctlptl.snapshot=base")
+	assert.Contains(t, build, "dev.tilt.ctlptl.snapshot.node=kind-control-plane")
+	assert.Contains(t, build, "dev.tilt.ctlptl.snapshot.role=control-plane")
+	assert.True(t, strings.HasPrefix(build[len(build)-1], "/"), "build context should be a temp dir")
+}
+
+func TestCreateSnapshotFailureRemovesImages(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	f.dockerClient.containers = []container.Summary{
+		kindNode("kind-control-plane", "control-plane"),
+		kindNode("kind-worker", "worker"),
+	}
+	f.dockerClient.commitErrs = map[string]error{"kind-worker": fmt.Errorf("disk full")}
+	execs := [][]string{}
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		execs = append(execs, argv)
+		return ""
+	})
+
+	_, err := f.controller.CreateSnapshot(context.Background(), "kind-kind", "base")
+	if assert.Error(t, err) {
+		assert.Equal(t, "snapshotting node kind-worker: disk full", err.Error())
+	}
+	assert.Equal(t, "kind-kind", admin.started.Name)
+	require.Len(t, execs, 3)
+	assert.Equal(t, []string{"docker", "rmi", "ctlptl-snapshot/base:kind-control-plane"}, execs[2])
+}
+
+func TestCreateSnapshotInterruptedRestartsCluster(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	f.dockerClient.containers = []container.Summary{
+		kindNode("kind-control-plane", "control-plane"),
+	}
+
+	// Simulate a Ctrl-C while we copy the node.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		cancel()
+		return ""
+	})
+
+	_, _ = f.controller.CreateSnapshot(ctx, "kind-kind", "base")
+	require.NotNil(t, admin.started)
+	assert.Equal(t, "kind-kind", admin.started.Name)
+}
+
+func TestCreateSnapshotInvalidName(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.CreateSnapshot(context.Background(), "kind-kind", "My Snapshot")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid snapshot name "My Snapshot"`)
+	}
+}
+
+func TestCreateSnapshotNotKind(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.CreateSnapshot(context.Background(), "microk8s", "base")
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster microk8s: snapshots are only supported for kind clusters", err.Error())
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	f := newFixture(t)
+	created := time.Unix(1700000000, 0)
+	f.dockerClient.images = []image.Summary{
+		snapshotImage("base", "kind-worker", "worker", created.Add(time.Minute)),
+		snapshotImage("base", "kind-control-plane", "control-plane", created),
+		snapshotImage("after-setup", "kind-control-plane", "control-plane", created),
+		{ID: "sha256:unrelated", Labels: map[string]string{"other": "label"}},
+	}
+
+	list, err := f.controller.ListSnapshots(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 2)
+	assert.Equal(t, "after-setup", list.Items[0].Name)
+
+	base := list.Items[1]
+	assert.Equal(t, "base", base.Name)
+	assert.Equal(t, "kind-kind", base.Cluster)
+	assert.Equal(t, "ctlptl-registry", base.Spec.Registry)
+	assert.Equal(t, created, base.CreationTimestamp.Time)
+	assert.Equal(t, []api.SnapshotNode{
+		{Name: "kind-control-plane", Role: "control-plane", Image: "ctlptl-snapshot/base:kind-control-plane", Size: 1000},
+		{Name: "kind-worker", Role: "worker", Image: "ctlptl-snapshot/base:kind-worker", Size: 1000},
+	}, base.Nodes)
+
+	_, err = f.controller.GetSnapshot(context.Background(), "missing")
+	if assert.Error(t, err) {
+		assert.Equal(t, `snapshots.ctlptl.dev "missing" not found`, err.Error())
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	admin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.dockerClient.images = []image.Summary{
+		snapshotImage("base", "kind-worker", "worker", time.Now()),
+		snapshotImage("base", "kind-control-plane", "control-plane", time.Now()),
+	}
+
+	cluster, err := f.controller.RestoreSnapshot(context.Background(), "base")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", cluster.Name)
+	assert.Equal(t, "ctlptl-registry", f.registryCtl.lastApply.Name)
+	assert.Equal(t, "", admin.created.KubernetesVersion)
+	assert.Equal(t, []v1alpha4.Node{
+		{Role: "control-plane", Image: "ctlptl-snapshot/base:kind-control-plane"},
+		{Role: "worker", Image: "ctlptl-snapshot/base:kind-worker"},
+	}, admin.created.KindV1Alpha4Cluster.Nodes)
+
+	// The cluster records the spec it was snapshotted with.
+	assert.Equal(t, "v1.27.3", cluster.KubernetesVersion)
+	assert.Nil(t, cluster.KindV1Alpha4Cluster)
+}
+
+func TestRestoreSnapshotClusterExists(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+	f.dockerClient.images = []image.Summary{
+		snapshotImage("base", "kind-control-plane", "control-plane", time.Now()),
+	}
+
+	_, err := f.controller.RestoreSnapshot(context.Background(), "base")
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster kind-kind already exists. Delete it before restoring snapshot base", err.Error())
+	}
+}
+
+func TestSnapshotKindConfigMismatch(t *testing.T) {
+	_, err := snapshotKindConfig(&v1alpha4.Cluster{
+		Nodes: []v1alpha4.Node{{Role: v1alpha4.ControlPlaneRole}},
+	}, []api.SnapshotNode{
+		{Name: "kind-control-plane", Role: "control-plane", Image: "cp"},
+		{Name: "kind-worker", Role: "worker", Image: "w"},
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "1 saved worker nodes aren't in the kind config", err.Error())
+	}
+}
+
+func kindNode(name, role string) container.Summary {
+	return container.Summary{
+		ID:     name + "-id",
+		Names:  []string{"/" + name},
+		Labels: map[string]string{kindClusterLabel: "kind", kindRoleLabel: role},
+	}
+}
+
+func snapshotImage(snapshot, node, role string, created time.Time) image.Summary {
+	return image.Summary{
+		ID:       "sha256:" + snapshot + node,
+		RepoTags: []string{"ctlptl-snapshot/" + snapshot + ":" + node},
+		Created:  created.Unix(),
+		Size:     1000,
+		Labels: map[string]string{
+			snapshotLabel:        snapshot,
+			snapshotClusterLabel: "kind-kind",
+			snapshotNodeLabel:    node,
+			snapshotRoleLabel:    role,
+			snapshotSpecLabel: `apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+name: kind-kind
+product: kind
+registry: ctlptl-registry
+kubernetesVersion: v1.27.3
+`,
+		},
+	}
+}
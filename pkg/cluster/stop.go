@@ -0,0 +1,130 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// Stops a cluster without deleting it.
+//
+// Also stops the cluster's registry, unless another running cluster uses it.
+func (c *Controller) Stop(ctx context.Context, name string) error {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	admin, err := c.stopAdmin(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Stopping cluster %q...\n", cluster.Name)
+	err = admin.Stop(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	if cluster.Registry == "" {
+		return nil
+	}
+
+	shared, err := c.registryInUse(ctx, cluster.Registry, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
+
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return err
+	}
+	return registryCtl.Stop(ctx, cluster.Registry)
+}
+
+// Starts a stopped cluster, and waits for it to be healthy.
+//
+// Also starts the cluster's registry, if it's stopped.
+func (c *Controller) Start(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := c.stopAdmin(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Starting cluster %q...\n", cluster.Name)
+	err = admin.Start(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.waitForHealthCheckAfterCreate(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// The registry is recorded in the cluster, so we can only
+	// read it once the cluster is up.
+	cluster, err = c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Registry == "" {
+		return cluster, nil
+	}
+
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = registryCtl.Start(ctx, cluster.Registry)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+func (c *Controller) stopAdmin(ctx context.Context, cluster *api.Cluster) (AdminWithStop, error) {
+	_, err := c.machine(ctx, cluster.Name, clusterid.Product(cluster.Product))
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := c.admin(ctx, clusterid.Product(cluster.Product))
+	if err != nil {
+		return nil, err
+	}
+
+	stopAdmin, ok := admin.(AdminWithStop)
+	if !ok {
+		return nil, fmt.Errorf("cluster %s: stopping and starting %s clusters is not supported", cluster.Name, cluster.Product)
+	}
+	return stopAdmin, nil
+}
+
+// Checks whether any running cluster besides the given one uses the registry.
+func (c *Controller) registryInUse(ctx context.Context, registry string, except string) (bool, error) {
+	clusters, err := c.List(ctx, ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, cluster := range clusters.Items {
+		if cluster.Name == except || cluster.Status.Error != "" {
+			continue
+		}
+		if cluster.Registry == registry {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestClusterStop(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	err := f.controller.Stop(context.Background(), "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", admin.stopped.Name)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastStop)
+}
+
+func TestClusterStopSharedRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	// Another context for the same fake cluster sees the same registry.
+	f.controller.config.Contexts["microk8s-2"] = &clientcmdapi.Context{Cluster: "microk8s-cluster"}
+
+	err := f.controller.Stop(context.Background(), "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", admin.stopped.Name)
+	assert.Equal(t, "", f.registryCtl.lastStop)
+}
+
+func TestClusterStopUnsupported(t *testing.T) {
+	f := newFixture(t)
+	f.controller.admins[clusterid.ProductMicroK8s] = struct{ Admin }{newFakeAdmin(f.config, f.fakeK8s)}
+
+	err := f.controller.Stop(context.Background(), "microk8s")
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster microk8s: stopping and starting microk8s clusters is not supported", err.Error())
+	}
+}
+
+func TestClusterStart(t *testing.T) {
+	f := newFixture(t)
+	f.setRegistry("kind-registry")
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	cluster, err := f.controller.Start(context.Background(), "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", admin.started.Name)
+	assert.Equal(t, "kind-registry", cluster.Registry)
+	assert.Equal(t, "kind-registry", f.registryCtl.lastStart)
+}
+
+func TestClusterStartNoRegistry(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newFakeAdmin(clusterid.ProductMicroK8s)
+
+	_, err := f.controller.Start(context.Background(), "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", admin.started.Name)
+	assert.Equal(t, "", f.registryCtl.lastStart)
+}
+
+// Records a registry in the cluster, the way ctlptl does on create.
+func (f *fixture) setRegistry(name string) {
+	_, err := f.fakeK8s.CoreV1().ConfigMaps("kube-public").Create(context.Background(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "local-registry-hosting", Namespace: "kube-public"},
+		Data:       map[string]string{"localRegistryHosting.v1": "host: localhost:5005\n"},
+	}, metav1.CreateOptions{})
+	require.NoError(f.t, err)
+	f.registryCtl.lastApply = &api.Registry{
+		Name:   name,
+		Status: api.RegistryStatus{HostPort: 5005, ContainerPort: 5000},
+	}
+}
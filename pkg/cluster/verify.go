@@ -0,0 +1,109 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+)
+
+// The result of pulling the test image on a node.
+type PullResult struct {
+	Node  string
+	Image string
+
+	// Empty if the pull succeeded.
+	Error string
+}
+
+// The result of checking that every node can pull from the cluster's registry.
+type Verification struct {
+	Cluster  string
+	Registry string
+
+	// The image we pushed, without the registry host.
+	Image string
+
+	Results []PullResult
+}
+
+func (v *Verification) Failed() bool {
+	for _, r := range v.Results {
+		if r.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks that the cluster's nodes can pull from its registry.
+//
+// Pushes a tiny image to the registry from the host, then pulls it
+// from each node under both the localhost:port and name:port aliases.
+func (c *Controller) Verify(ctx context.Context, name string) (*Verification, error) {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Registry == "" {
+		return nil, fmt.Errorf("cluster %s has no registry", name)
+	}
+	if cluster.Status.Error != "" {
+		return nil, fmt.Errorf("cluster %s: %s", name, cluster.Status.Error)
+	}
+
+	reg, err := c.describeRegistry(ctx, cluster.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	registryCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+	image, err := registryCtl.PushTestImage(ctx, reg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := c.client(name)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := k8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dockerCLI, err := c.getDockerCLI(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bin := dctr.Binary(dockerCLI.Client())
+
+	images := []string{
+		fmt.Sprintf("localhost:%d/%s", reg.Status.HostPort, image),
+		fmt.Sprintf("%s:%d/%s", reg.Name, reg.Status.ContainerPort, image),
+	}
+
+	result := &Verification{Cluster: name, Registry: reg.Name, Image: image}
+	for _, node := range nodes.Items {
+		for _, img := range images {
+			out := bytes.NewBuffer(nil)
+			err := c.runner.RunIO(ctx,
+				genericclioptions.IOStreams{Out: out, ErrOut: out},
+				bin, "exec", node.Name, "crictl", "pull", img)
+			pull := PullResult{Node: node.Name, Image: img}
+			if err != nil {
+				pull.Error = strings.TrimSpace(fmt.Sprintf("%v %s", err, out.String()))
+			}
+			result.Results = append(result.Results, pull)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+// The label that kind, k3d, minikube, and Docker Desktop
+// all put on their cluster DNS deployments.
+const clusterDNSSelector = "k8s-app=kube-dns"
+
+// Returns the cluster's readiness gates, with unset gates turned on.
+func waitGates(cluster *api.Cluster) api.ClusterWait {
+	gates := api.ClusterWait{}
+	if cluster.Wait != nil {
+		gates = *cluster.Wait.DeepCopy()
+	}
+	on := func(gate **bool) {
+		if *gate == nil {
+			t := true
+			*gate = &t
+		}
+	}
+	on(&gates.NodesReady)
+	on(&gates.CoreDNS)
+	on(&gates.DefaultServiceAccount)
+	return gates
+}
+
+// Returns the gates to check before the cluster's manifests are applied.
+//
+// The manifests may install the CNI (e.g., with kind's disableDefaultCNI),
+// and nodes and cluster DNS can't become ready without one. So we
+// check those gates after the manifests are applied instead.
+func gatesBeforeManifests(cluster *api.Cluster) api.ClusterWait {
+	gates := waitGates(cluster)
+	if len(cluster.Manifests) > 0 {
+		off := false
+		gates.NodesReady = &off
+		gates.CoreDNS = &off
+	}
+	return gates
+}
+
+// Waits until the cluster passes its readiness gates.
+func (c *Controller) WaitReady(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	err = c.waitForHealthCheckAfterCreate(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, name)
+}
+
+// Checks that the cluster is healthy, then checks each of the readiness gates.
+// Returns an error that describes the first gate that isn't ready yet.
+func (c *Controller) checkReady(ctx context.Context, client kubernetes.Interface, gates api.ClusterWait) error {
+	// quick apiserver health check.
+	_, err := c.healthCheckCluster(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	// make sure the kube-public namespace exists,
+	// because this is where ctlptl writes its configs.
+	_, err = client.CoreV1().Namespaces().Get(ctx, "kube-public", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if *gates.NodesReady {
+		err := nodesReady(ctx, client)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *gates.CoreDNS {
+		err := clusterDNSAvailable(ctx, client)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *gates.DefaultServiceAccount {
+		_, err := client.CoreV1().ServiceAccounts("default").Get(ctx, "default", metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("default service account: %v", err)
+		}
+	}
+	return nil
+}
+
+func nodesReady(ctx context.Context, client kubernetes.Interface) error {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %v", err)
+	}
+	if len(nodes.Items) == 0 {
+		return fmt.Errorf("no nodes registered")
+	}
+	for _, node := range nodes.Items {
+		if !nodeReady(node) {
+			return fmt.Errorf("node %s is not ready", node.Name)
+		}
+	}
+	return nil
+}
+
+func nodeReady(node corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func clusterDNSAvailable(ctx context.Context, client kubernetes.Interface) error {
+	deployments, err := client.AppsV1().Deployments("kube-system").List(ctx, metav1.ListOptions{
+		LabelSelector: clusterDNSSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("listing cluster DNS: %v", err)
+	}
+	if len(deployments.Items) == 0 {
+		return fmt.Errorf("cluster DNS not found")
+	}
+	for _, d := range deployments.Items {
+		if !deploymentAvailable(&d) {
+			return fmt.Errorf("cluster DNS deployment %s is not available", d.Name)
+		}
+	}
+	return nil
+}
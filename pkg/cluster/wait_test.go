@@ -0,0 +1,154 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestClusterApplyWaitsForNodesReady(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.setNodeReady("node-1", v1.ConditionFalse)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out waiting for cluster to start: node node-1 is not ready")
+	}
+}
+
+func TestClusterApplyWaitsForCoreDNS(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	err := f.fakeK8s.AppsV1().Deployments("kube-system").Delete(context.Background(), "coredns", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cluster DNS not found")
+	}
+}
+
+func TestClusterApplyWaitsForDefaultServiceAccount(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	err := f.fakeK8s.CoreV1().ServiceAccounts("default").Delete(context.Background(), "default", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "default service account")
+	}
+}
+
+func TestClusterApplyGatesOff(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.setNodeReady("node-1", v1.ConditionUnknown)
+	err := f.fakeK8s.AppsV1().Deployments("kube-system").Delete(context.Background(), "coredns", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Wait:    &api.ClusterWait{NodesReady: boolPtr(false), CoreDNS: boolPtr(false)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &api.ClusterWait{NodesReady: boolPtr(false), CoreDNS: boolPtr(false)}, result.Wait)
+}
+
+func TestClusterApplyCNIFromManifests(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	f.newFakeAdmin(clusterid.ProductKIND)
+	f.setNodeReady("node-1", v1.ConditionFalse)
+	f.fakeDynamicClient()
+	path := f.writeManifest("cni.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cni-config
+  namespace: kube-system
+`)
+
+	// With the default CNI disabled, the node only becomes ready
+	// once the CNI in the manifests is installed.
+	load := f.controller.dynamicClientLoader
+	f.controller.dynamicClientLoader = func(config *rest.Config) (dynamic.Interface, meta.RESTMapper, error) {
+		client, mapper, err := load(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		client.(*dynamicfake.FakeDynamicClient).PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			f.setNodeReady("node-1", v1.ConditionTrue)
+			return false, nil, nil
+		})
+		return client, mapper, nil
+	}
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		KindV1Alpha4Cluster: &v1alpha4.Cluster{
+			Networking: v1alpha4.Networking{DisableDefaultCNI: true},
+		},
+		Manifests: []string{path},
+	})
+	require.NoError(t, err)
+}
+
+func TestWaitReady(t *testing.T) {
+	f := newFixture(t)
+	f.setNodeReady("node-1", v1.ConditionFalse)
+
+	_, err := f.controller.WaitReady(context.Background(), "microk8s")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "node node-1 is not ready")
+	}
+
+	f.setNodeReady("node-1", v1.ConditionTrue)
+	cluster, err := f.controller.WaitReady(context.Background(), "microk8s")
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s", cluster.Name)
+}
+
+func TestWaitGatesDefaults(t *testing.T) {
+	gates := waitGates(&api.Cluster{Wait: &api.ClusterWait{CoreDNS: boolPtr(false)}})
+	assert.True(t, *gates.NodesReady)
+	assert.False(t, *gates.CoreDNS)
+	assert.True(t, *gates.DefaultServiceAccount)
+}
+
+func (f *fixture) setNodeReady(name string, status v1.ConditionStatus) {
+	node, err := f.fakeK8s.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(f.t, err)
+	node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}
+	_, err = f.fakeK8s.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	require.NoError(f.t, err)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
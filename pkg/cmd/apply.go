@@ -2,15 +2,23 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/tilt-dev/clusterid"
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/config"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
 	"github.com/tilt-dev/ctlptl/pkg/registry"
 	"github.com/tilt-dev/ctlptl/pkg/visitor"
 )
@@ -21,6 +29,34 @@ type ApplyOptions struct {
 	genericclioptions.IOStreams
 
 	Filenames []string
+
+	// Check that clusters can pull from their registries after applying them.
+	Verify bool
+
+	// Override the timeouts in the ctlptl config.
+	Timeout       time.Duration
+	HealthTimeout time.Duration
+
+	// Leave clusters that fail to create behind, for debugging.
+	KeepOnFailure bool
+
+	// How to report progress: text or json.
+	Progress string
+
+	// Apply clusters concurrently.
+	Parallel bool
+
+	// Delete managed clusters and registries that match the selector,
+	// but aren't in the applied files.
+	Prune         bool
+	PruneSelector string
+
+	// With Prune, report what would be pruned, without changing anything.
+	DryRun bool
+
+	progress                 progress.Reporter
+	registryController       registryApplier
+	clusterControllerFactory func(streams genericclioptions.IOStreams) (clusterApplier, error)
 }
 
 func NewApplyOptions() *ApplyOptions {
@@ -45,6 +81,19 @@ func (o *ApplyOptions) Command() *cobra.Command {
 	cmd.SetErr(o.ErrOut)
 	o.FileNameFlags.AddFlags(cmd.Flags())
 	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.Verify, "verify", o.Verify,
+		"After applying each cluster, check that each node can pull images from the cluster's registry")
+	addTimeoutFlags(cmd, &o.Timeout, &o.HealthTimeout)
+	addKeepOnFailureFlag(cmd, &o.KeepOnFailure)
+	addProgressFlag(cmd, &o.Progress)
+	cmd.Flags().BoolVar(&o.Parallel, "parallel", o.Parallel,
+		"Apply clusters concurrently, after creating their registries. Prefixes each line of output with the cluster name")
+	cmd.Flags().BoolVar(&o.Prune, "prune", o.Prune,
+		"After applying, delete the clusters and registries that ctlptl created, that match --prune-selector, and that aren't in the applied files. Also deletes the registries of deleted clusters, unless another cluster uses them")
+	cmd.Flags().StringVar(&o.PruneSelector, "prune-selector", o.PruneSelector,
+		"Label selector (e.g., env=ci) that limits which clusters and registries --prune may delete. Required with --prune")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun,
+		"With --prune, print the clusters and registries that would be pruned, without applying or deleting anything")
 
 	return cmd
 }
@@ -55,11 +104,20 @@ func (o *ApplyOptions) Run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	err := o.run()
+	reporter, flush, err := newProgressReporter(o.Progress, &o.IOStreams)
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
 		os.Exit(1)
 	}
+	o.progress = reporter
+
+	err = o.run()
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
+		os.Exit(1)
+	}
+	flush()
 }
 
 func (o *ApplyOptions) run() error {
@@ -70,7 +128,18 @@ func (o *ApplyOptions) run() error {
 	a.Incr("cmd.apply", nil)
 	defer a.Flush(time.Second)
 
-	ctx := context.TODO()
+	err = validateTimeoutFlags(o.Timeout, o.HealthTimeout)
+	if err != nil {
+		return err
+	}
+
+	err = o.validatePrune()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
 
 	printer, err := o.ToPrinter()
 	if err != nil {
@@ -87,16 +156,27 @@ func (o *ApplyOptions) run() error {
 		return err
 	}
 
-	var cc *cluster.Controller
-	var rc *registry.Controller
+	if !o.DryRun {
+		err = o.apply(ctx, printer, objects)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.Prune {
+		return o.prune(ctx, objects)
+	}
+	return nil
+}
+
+// Applies the registries, then the clusters.
+func (o *ApplyOptions) apply(ctx context.Context, printer printers.ResourcePrinter, objects []runtime.Object) error {
 	for _, obj := range objects {
 		switch obj := obj.(type) {
 		case *api.Registry:
-			if rc == nil {
-				rc, err = registry.DefaultController(o.IOStreams)
-				if err != nil {
-					return err
-				}
+			rc, err := o.getRegistryController()
+			if err != nil {
+				return err
 			}
 
 			newObj, err := rc.Apply(ctx, obj)
@@ -111,22 +191,23 @@ func (o *ApplyOptions) run() error {
 		}
 	}
 
+	if o.Parallel {
+		return o.applyClustersInParallel(ctx, printer, objects)
+	}
+
+	var cc clusterApplier
 	for _, obj := range objects {
 		switch obj := obj.(type) {
 		case *api.Cluster:
 			if cc == nil {
-				cc, err = cluster.DefaultController(o.IOStreams)
+				var err error
+				cc, err = o.newClusterController(o.IOStreams)
 				if err != nil {
 					return err
 				}
 			}
 
-			newObj, err := cc.Apply(ctx, obj)
-			if err != nil {
-				return err
-			}
-
-			err = printer.PrintObj(newObj, o.Out)
+			err := o.applyCluster(ctx, cc, printer, obj, o.IOStreams)
 			if err != nil {
 				return err
 			}
@@ -141,3 +222,213 @@ func (o *ApplyOptions) run() error {
 	}
 	return nil
 }
+
+// Applies the cluster, then verifies and prints it.
+func (o *ApplyOptions) applyCluster(ctx context.Context, cc clusterApplier, printer printers.ResourcePrinter, obj *api.Cluster, streams genericclioptions.IOStreams) error {
+	newObj, err := cc.Apply(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	if o.Verify && newObj.Registry != "" {
+		err = verifyCluster(ctx, cc, newObj.Name, streams.ErrOut)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printer.PrintObj(newObj, streams.Out)
+}
+
+// Applies clusters concurrently, each with its own controller,
+// and with each line of output prefixed by the cluster name.
+//
+// Creates the clusters' registries first, so that clusters that share
+// a registry don't race to create it, and so that one cluster's
+// rollback never deletes a registry that another cluster is using.
+func (o *ApplyOptions) applyClustersInParallel(ctx context.Context, printer printers.ResourcePrinter, objects []runtime.Object) error {
+	var clusters []*api.Cluster
+	names := make(map[string]bool)
+	for _, obj := range objects {
+		switch obj := obj.(type) {
+		case *api.Cluster:
+			if obj.Product == string(clusterid.ProductDockerDesktop) {
+				return fmt.Errorf("cannot apply docker-desktop clusters with --parallel: " +
+					"setting up docker-desktop may restart the Docker VM that other clusters run in")
+			}
+			cluster.FillDefaults(obj)
+			if names[obj.Name] {
+				return fmt.Errorf("cannot apply cluster %s twice with --parallel", obj.Name)
+			}
+			names[obj.Name] = true
+			clusters = append(clusters, obj)
+		case *api.Registry:
+			continue
+		default:
+			return fmt.Errorf("unrecognized type: %T", obj)
+		}
+	}
+
+	for _, c := range clusters {
+		reg := cluster.RegistryFor(c)
+		if reg == nil {
+			continue
+		}
+		rc, err := o.getRegistryController()
+		if err != nil {
+			return err
+		}
+		_, err = rc.Apply(ctx, reg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var outMu, errOutMu sync.Mutex
+	errs := make([]error, len(clusters))
+	var wg sync.WaitGroup
+	for i, c := range clusters {
+		prefix := fmt.Sprintf("[%s] ", c.Name)
+		out := newPrefixWriter(&outMu, o.Out, prefix)
+		errOut := newPrefixWriter(&errOutMu, o.ErrOut, prefix)
+		streams := genericclioptions.IOStreams{In: o.In, Out: out, ErrOut: errOut}
+		wg.Go(func() {
+			defer func() {
+				_ = out.Flush()
+				_ = errOut.Flush()
+			}()
+
+			cc, err := o.newClusterController(streams)
+			if err == nil {
+				err = o.applyCluster(ctx, cc, printer, c, streams)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("cluster %s: %v", c.Name, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return err
+	}
+
+	// Each cluster switches the kubectl context when it's done.
+	// Leave the context on the last cluster, like a sequential apply does.
+	if len(clusters) > 0 {
+		cc, err := o.newClusterController(o.IOStreams)
+		if err != nil {
+			return err
+		}
+		return cc.UseContext(clusters[len(clusters)-1].Name)
+	}
+	return nil
+}
+
+func (o *ApplyOptions) validatePrune() error {
+	if o.Prune && o.PruneSelector == "" {
+		return fmt.Errorf("--prune requires --prune-selector, to limit which clusters and registries it may delete")
+	}
+	if !o.Prune && o.PruneSelector != "" {
+		return fmt.Errorf("--prune-selector requires --prune")
+	}
+	if !o.Prune && o.DryRun {
+		return fmt.Errorf("--dry-run requires --prune")
+	}
+	return nil
+}
+
+// Deletes the managed clusters and registries that match the prune selector,
+// but aren't in the applied files, then prints them.
+func (o *ApplyOptions) prune(ctx context.Context, objects []runtime.Object) error {
+	options := cluster.PruneAppliedOptions{
+		LabelSelector: o.PruneSelector,
+		DryRun:        o.DryRun,
+	}
+	for _, obj := range objects {
+		switch obj := obj.(type) {
+		case *api.Cluster:
+			cluster.FillDefaults(obj)
+			options.Clusters = append(options.Clusters, obj.Name)
+			if obj.Registry != "" {
+				options.Registries = append(options.Registries, obj.Registry)
+			}
+		case *api.Registry:
+			options.Registries = append(options.Registries, obj.Name)
+		default:
+			return fmt.Errorf("unrecognized type: %T", obj)
+		}
+	}
+
+	cc, err := o.newClusterController(o.IOStreams)
+	if err != nil {
+		return err
+	}
+	result, err := cc.PruneApplied(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	flags := *o.PrintFlags
+	flags.NamePrintFlags = genericclioptions.NewNamePrintFlags("pruned")
+	if o.DryRun {
+		flags.Complete("%s (dry run)")
+	}
+	printer, err := flags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Clusters {
+		err := printer.PrintObj(&result.Clusters[i], o.Out)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range result.Registries {
+		err := printer.PrintObj(&result.Registries[i], o.Out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *ApplyOptions) getRegistryController() (registryApplier, error) {
+	if o.registryController == nil {
+		rc, err := registry.DefaultController(o.IOStreams)
+		if err != nil {
+			return nil, err
+		}
+		rc.SetProgress(o.progress)
+		o.registryController = rc
+	}
+	return o.registryController, nil
+}
+
+// Creates a cluster controller with the timeouts and other settings from the flags.
+func (o *ApplyOptions) newClusterController(streams genericclioptions.IOStreams) (clusterApplier, error) {
+	if o.clusterControllerFactory != nil {
+		return o.clusterControllerFactory(streams)
+	}
+	cc, err := cluster.DefaultController(streams)
+	if err != nil {
+		return nil, err
+	}
+	cc.SetTimeouts(config.Timeouts{Create: o.Timeout, HealthCheck: o.HealthTimeout})
+	cc.SetKeepOnFailure(o.KeepOnFailure)
+	cc.SetProgress(o.progress)
+	return cc, nil
+}
+
+type clusterApplier interface {
+	clusterVerifier
+	Apply(ctx context.Context, cluster *api.Cluster) (*api.Cluster, error)
+	UseContext(name string) error
+	PruneApplied(ctx context.Context, options cluster.PruneAppliedOptions) (*cluster.PruneAppliedResult, error)
+}
+
+type registryApplier interface {
+	Apply(ctx context.Context, registry *api.Registry) (*api.Registry, error)
+}
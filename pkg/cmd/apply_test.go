@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+const twoKindClusters = `
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+name: kind-a
+registry: shared-registry
+---
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+name: kind-b
+registry: shared-registry
+`
+
+func TestApply(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	f := newFakeClusterAppliers(0)
+	o.clusterControllerFactory = f.newController
+	o.registryController = &fakeRegistryController{}
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, twoKindClusters)
+
+	err := o.run()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-a", "kind-b"}, f.applied)
+	assert.Equal(t,
+		"cluster.ctlptl.dev/kind-a created\n"+
+			"cluster.ctlptl.dev/kind-b created\n",
+		out.String())
+}
+
+func TestApplyParallel(t *testing.T) {
+	streams, in, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Parallel = true
+
+	// Each apply blocks until both have started,
+	// so this only passes if they run concurrently.
+	f := newFakeClusterAppliers(2)
+	o.clusterControllerFactory = f.newController
+	rc := &fakeRegistryController{}
+	o.registryController = rc
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, twoKindClusters)
+
+	err := o.run()
+	require.NoError(t, err)
+
+	sort.Strings(f.applied)
+	assert.Equal(t, []string{"kind-a", "kind-b"}, f.applied)
+	assert.Equal(t, "shared-registry", rc.lastRegistry.Name)
+	assert.Contains(t, out.String(), "[kind-a] cluster.ctlptl.dev/kind-a created\n")
+	assert.Contains(t, out.String(), "[kind-b] cluster.ctlptl.dev/kind-b created\n")
+	assert.Contains(t, errOut.String(), "[kind-a] Creating cluster kind-a\n")
+	assert.Contains(t, errOut.String(), "[kind-b] Creating cluster kind-b\n")
+	assert.Equal(t, "kind-b", f.currentContext)
+}
+
+func TestApplyParallelError(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Parallel = true
+	f := newFakeClusterAppliers(0)
+	f.errs["kind-a"] = fmt.Errorf("kind create failed")
+	o.clusterControllerFactory = f.newController
+	o.registryController = &fakeRegistryController{}
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, twoKindClusters)
+
+	err := o.run()
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster kind-a: kind create failed", err.Error())
+	}
+
+	// The other cluster still finishes.
+	assert.Equal(t, []string{"kind-b"}, f.applied)
+	assert.Equal(t, "", f.currentContext)
+}
+
+func TestApplyParallelDockerDesktop(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Parallel = true
+	f := newFakeClusterAppliers(0)
+	o.clusterControllerFactory = f.newController
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, `
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: docker-desktop
+---
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+`)
+
+	err := o.run()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot apply docker-desktop clusters with --parallel")
+	}
+	assert.Empty(t, f.applied)
+}
+
+func TestApplyParallelDuplicate(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Parallel = true
+	f := newFakeClusterAppliers(0)
+	o.clusterControllerFactory = f.newController
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, `
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+---
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+name: kind-kind
+`)
+
+	err := o.run()
+	if assert.Error(t, err) {
+		assert.Equal(t, "cannot apply cluster kind-kind twice with --parallel", err.Error())
+	}
+}
+
+func TestApplyPrune(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Prune = true
+	o.PruneSelector = "env=ci"
+	f := newFakeClusterAppliers(0)
+	f.pruneResult = cluster.PruneAppliedResult{
+		Clusters:   []api.Cluster{{TypeMeta: cluster.TypeMeta(), Name: "kind-old"}},
+		Registries: []api.Registry{{TypeMeta: registry.TypeMeta(), Name: "old-registry"}},
+	}
+	o.clusterControllerFactory = f.newController
+	o.registryController = &fakeRegistryController{}
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, `
+apiVersion: ctlptl.dev/v1alpha1
+kind: Registry
+name: ci-registry
+---
+apiVersion: ctlptl.dev/v1alpha1
+kind: Cluster
+product: kind
+registry: shared-registry
+`)
+
+	err := o.run()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-kind"}, f.applied)
+	assert.Equal(t, cluster.PruneAppliedOptions{
+		Clusters:      []string{"kind-kind"},
+		Registries:    []string{"ci-registry", "shared-registry"},
+		LabelSelector: "env=ci",
+	}, f.pruneOptions)
+	assert.Equal(t,
+		"registry.ctlptl.dev/ci-registry created\n"+
+			"cluster.ctlptl.dev/kind-kind created\n"+
+			"cluster.ctlptl.dev/kind-old pruned\n"+
+			"registry.ctlptl.dev/old-registry pruned\n",
+		out.String())
+}
+
+func TestApplyPruneDryRun(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Prune = true
+	o.PruneSelector = "env=ci"
+	o.DryRun = true
+	f := newFakeClusterAppliers(0)
+	f.pruneResult = cluster.PruneAppliedResult{
+		Clusters: []api.Cluster{{TypeMeta: cluster.TypeMeta(), Name: "kind-old"}},
+	}
+	o.clusterControllerFactory = f.newController
+	o.Filenames = []string{"-"}
+	_, _ = io.WriteString(in, twoKindClusters)
+
+	err := o.run()
+	require.NoError(t, err)
+	assert.Empty(t, f.applied)
+	assert.True(t, f.pruneOptions.DryRun)
+	assert.Equal(t, []string{"kind-a", "kind-b"}, f.pruneOptions.Clusters)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-old pruned (dry run)\n", out.String())
+}
+
+func TestApplyPruneFlags(t *testing.T) {
+	for _, tc := range []struct {
+		prune    bool
+		selector string
+		dryRun   bool
+		expected string
+	}{
+		{true, "", false, "--prune requires --prune-selector"},
+		{false, "env=ci", false, "--prune-selector requires --prune"},
+		{false, "", true, "--dry-run requires --prune"},
+	} {
+		t.Run(tc.expected, func(t *testing.T) {
+			o := NewApplyOptions()
+			o.Prune = tc.prune
+			o.PruneSelector = tc.selector
+			o.DryRun = tc.dryRun
+			err := o.run()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestApplyNegativeTimeout(t *testing.T) {
+	o := NewApplyOptions()
+	o.Timeout = -time.Minute
+	err := o.run()
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid --timeout -1m0s: must be a positive duration, like 5m", err.Error())
+	}
+}
+
+// Fake cluster controllers that share their state,
+// like real controllers share the kubeconfig.
+type fakeClusterAppliers struct {
+	mu             sync.Mutex
+	applied        []string
+	errs           map[string]error
+	currentContext string
+
+	pruneOptions cluster.PruneAppliedOptions
+	pruneResult  cluster.PruneAppliedResult
+
+	// Applies wait until this many have started.
+	concurrent int
+	barrier    sync.WaitGroup
+}
+
+func newFakeClusterAppliers(concurrent int) *fakeClusterAppliers {
+	f := &fakeClusterAppliers{errs: make(map[string]error), concurrent: concurrent}
+	f.barrier.Add(concurrent)
+	return f
+}
+
+func (f *fakeClusterAppliers) newController(streams genericclioptions.IOStreams) (clusterApplier, error) {
+	return &fakeClusterApplier{f: f, streams: streams}, nil
+}
+
+type fakeClusterApplier struct {
+	f       *fakeClusterAppliers
+	streams genericclioptions.IOStreams
+}
+
+func (a *fakeClusterApplier) Apply(ctx context.Context, c *api.Cluster) (*api.Cluster, error) {
+	cluster.FillDefaults(c)
+	_, _ = fmt.Fprintf(a.streams.ErrOut, "Creating cluster %s\n", c.Name)
+
+	f := a.f
+	if f.concurrent > 0 {
+		f.barrier.Done()
+		done := make(chan struct{})
+		go func() {
+			f.barrier.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("timed out waiting for the other applies to start")
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.errs[c.Name]
+	if err != nil {
+		return nil, err
+	}
+	f.applied = append(f.applied, c.Name)
+	return c, nil
+}
+
+func (a *fakeClusterApplier) UseContext(name string) error {
+	a.f.mu.Lock()
+	defer a.f.mu.Unlock()
+	a.f.currentContext = name
+	return nil
+}
+
+func (a *fakeClusterApplier) PruneApplied(ctx context.Context, options cluster.PruneAppliedOptions) (*cluster.PruneAppliedResult, error) {
+	a.f.mu.Lock()
+	defer a.f.mu.Unlock()
+	a.f.pruneOptions = options
+	return &a.f.pruneResult, nil
+}
+
+func (a *fakeClusterApplier) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *fakeClusterApplier) Verify(ctx context.Context, name string) (*cluster.Verification, error) {
+	return nil, fmt.Errorf("not implemented")
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/config"
 )
 
 type CreateClusterOptions struct {
@@ -22,6 +23,19 @@ type CreateClusterOptions struct {
 	genericclioptions.IOStreams
 
 	Cluster *api.Cluster
+
+	// Check that the cluster can pull from its registry after creating it.
+	Verify bool
+
+	// Override the timeouts in the ctlptl config.
+	Timeout       time.Duration
+	HealthTimeout time.Duration
+
+	// Leave the cluster behind if it fails to create, for debugging.
+	KeepOnFailure bool
+
+	// How to report progress: text or json.
+	Progress string
 }
 
 func NewCreateClusterOptions() *CreateClusterOptions {
@@ -63,27 +77,45 @@ func (o *CreateClusterOptions) Command() *cobra.Command {
 		o.Cluster.Minikube.ExtraConfigs, "Minikube extra configs (only applicable to a minikube cluster)")
 	cmd.Flags().StringVar(&o.Cluster.Minikube.ContainerRuntime, "minikube-container-runtime",
 		o.Cluster.Minikube.ContainerRuntime, "Minikube container runtime (only applicable to a minikube cluster)")
+	cmd.Flags().BoolVar(&o.Verify, "verify", o.Verify,
+		"After creating the cluster, check that each node can pull images from the registry")
+	addTimeoutFlags(cmd, &o.Timeout, &o.HealthTimeout)
+	addKeepOnFailureFlag(cmd, &o.KeepOnFailure)
+	addProgressFlag(cmd, &o.Progress)
 
 	return cmd
 }
 
 func (o *CreateClusterOptions) Run(cmd *cobra.Command, args []string) {
+	reporter, flush, err := newProgressReporter(o.Progress, &o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
 	controller, err := cluster.DefaultController(o.IOStreams)
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
 		os.Exit(1)
 	}
+	controller.SetTimeouts(config.Timeouts{Create: o.Timeout, HealthCheck: o.HealthTimeout})
+	controller.SetKeepOnFailure(o.KeepOnFailure)
+	controller.SetProgress(reporter)
 
 	err = o.run(controller, args[0])
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
 		os.Exit(1)
 	}
+	flush()
 }
 
 type clusterCreator interface {
 	Apply(ctx context.Context, cluster *api.Cluster) (*api.Cluster, error)
 	Get(ctx context.Context, name string) (*api.Cluster, error)
+	Verify(ctx context.Context, name string) (*cluster.Verification, error)
 }
 
 func (o *CreateClusterOptions) run(controller clusterCreator, product string) error {
@@ -95,6 +127,11 @@ func (o *CreateClusterOptions) run(controller clusterCreator, product string) er
 	a.Incr("cmd.create.cluster", nil)
 	defer a.Flush(time.Second)
 
+	err = validateTimeoutFlags(o.Timeout, o.HealthTimeout)
+	if err != nil {
+		return err
+	}
+
 	o.Cluster.Product = product
 
 	// Zero out the minikube config if not used.
@@ -104,7 +141,8 @@ func (o *CreateClusterOptions) run(controller clusterCreator, product string) er
 
 	cluster.FillDefaults(o.Cluster)
 
-	ctx := context.Background()
+	ctx, cancel := signalContext()
+	defer cancel()
 	_, err = controller.Get(ctx, o.Cluster.Name)
 	if err == nil {
 		return fmt.Errorf("Cannot create cluster: already exists")
@@ -117,6 +155,13 @@ func (o *CreateClusterOptions) run(controller clusterCreator, product string) er
 		return err
 	}
 
+	if o.Verify && applied.Registry != "" {
+		err = verifyCluster(ctx, controller, applied.Name, o.ErrOut)
+		if err != nil {
+			return err
+		}
+	}
+
 	printer, err := o.ToPrinter()
 	if err != nil {
 		return err
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,6 +13,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
 )
 
 func TestCreateCluster(t *testing.T) {
@@ -25,11 +28,57 @@ func TestCreateCluster(t *testing.T) {
 	assert.Equal(t, "kind-kind", fcc.lastApplyName)
 }
 
+func TestCreateClusterNegativeTimeout(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.HealthTimeout = -time.Second
+
+	fcc := &fakeClusterController{}
+	err := o.run(fcc, "kind")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid --health-timeout -1s: must be a positive duration, like 3s", err.Error())
+	}
+	assert.Equal(t, "", fcc.lastApplyName)
+}
+
+func TestCreateClusterVerify(t *testing.T) {
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.Cluster.Registry = "ctlptl-registry"
+	o.Verify = true
+
+	fcc := &fakeClusterController{verification: &cluster.Verification{
+		Cluster:  "kind-kind",
+		Registry: "ctlptl-registry",
+		Image:    "ctlptl-verify:latest",
+		Results: []cluster.PullResult{
+			{Node: "kind-control-plane", Image: "localhost:5005/ctlptl-verify:latest"},
+			{Node: "kind-control-plane", Image: "ctlptl-registry:5000/ctlptl-verify:latest", Error: "exit status 1 connection refused"},
+		},
+	}}
+	err := o.run(fcc, "kind")
+	if assert.Error(t, err) {
+		assert.Equal(t, "cluster kind-kind: 1 of 2 pulls from registry ctlptl-registry failed", err.Error())
+	}
+	assert.Equal(t, "kind-kind", fcc.lastVerifyName)
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, `Pushed ctlptl-verify:latest to registry ctlptl-registry
+NODE                IMAGE                                      RESULT
+kind-control-plane  localhost:5005/ctlptl-verify:latest        ok
+kind-control-plane  ctlptl-registry:5000/ctlptl-verify:latest  failed: exit status 1 connection refused
+`, errOut.String())
+}
+
 type fakeClusterController struct {
 	clusters       map[string]*api.Cluster
 	lastApplyName  string
 	lastDeleteName string
 	nextError      error
+
+	verification   *cluster.Verification
+	lastVerifyName string
 }
 
 func (cd *fakeClusterController) Delete(ctx context.Context, name string) error {
@@ -57,3 +106,11 @@ func (cd *fakeClusterController) Get(ctx context.Context, name string) (*api.Clu
 	}
 	return nil, apierrors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
 }
+
+func (cd *fakeClusterController) Verify(ctx context.Context, name string) (*cluster.Verification, error) {
+	cd.lastVerifyName = name
+	if cd.verification == nil {
+		return nil, fmt.Errorf("cluster %s has no registry", name)
+	}
+	return cd.verification, nil
+}
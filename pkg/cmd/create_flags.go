@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for commands that create clusters.
+//
+// When a timeout flag isn't set, we fall back to the timeouts in ~/.ctlptl/config.yaml, then to the defaults.
+func addTimeoutFlags(cmd *cobra.Command, create, healthCheck *time.Duration) {
+	cmd.Flags().DurationVar(create, "timeout", *create,
+		"How long to wait for a new cluster to become healthy. Overrides timeouts.create in ~/.ctlptl/config.yaml. Defaults to 5m.")
+	cmd.Flags().DurationVar(healthCheck, "health-timeout", *healthCheck,
+		"How long to wait for a cluster to respond before reporting it as broken. Overrides timeouts.healthCheck in ~/.ctlptl/config.yaml. Defaults to 3s.")
+}
+
+// Zero means the flag isn't set, but negative timeouts are always a mistake.
+//
+// The config file is validated when it's loaded, so we only check the flags.
+func validateTimeoutFlags(create, healthCheck time.Duration) error {
+	if create < 0 {
+		return fmt.Errorf("invalid --timeout %s: must be a positive duration, like 5m", create)
+	}
+	if healthCheck < 0 {
+		return fmt.Errorf("invalid --health-timeout %s: must be a positive duration, like 3s", healthCheck)
+	}
+	return nil
+}
+
+func addKeepOnFailureFlag(cmd *cobra.Command, keep *bool) {
+	cmd.Flags().BoolVar(keep, "keep-on-failure", *keep,
+		"If creating a cluster fails or is interrupted, leave the half-created cluster behind for debugging, instead of deleting it")
+}
@@ -19,6 +19,12 @@ type CreateRegistryOptions struct {
 	genericclioptions.IOStreams
 
 	Registry *api.Registry
+
+	StorageVolume   string
+	StorageHostPath string
+
+	// How to report progress: text or json.
+	Progress string
 }
 
 func NewCreateRegistryOptions() *CreateRegistryOptions {
@@ -38,7 +44,8 @@ func (o *CreateRegistryOptions) Command() *cobra.Command {
 		Short: "Create a registry with the given name",
 		Example: "  ctlptl create registry ctlptl-registry\n" +
 			"  ctlptl create registry ctlptl-registry --port=5000\n" +
-			"  ctlptl create registry ctlptl-registry --port=5000 --listen-address 0.0.0.0",
+			"  ctlptl create registry ctlptl-registry --port=5000 --listen-address 0.0.0.0\n" +
+			"  ctlptl create registry ctlptl-registry --storage-volume ctlptl-registry-data",
 		Run:  o.Run,
 		Args: cobra.ExactArgs(1),
 	}
@@ -49,25 +56,47 @@ func (o *CreateRegistryOptions) Command() *cobra.Command {
 	cmd.Flags().IntVar(&o.Registry.Port, "port", o.Registry.Port,
 		"The port to expose the registry on host. If not specified, chooses a random port")
 	cmd.Flags().StringVar(&o.Registry.ListenAddress, "listen-address", o.Registry.ListenAddress,
-		"The host's IP address to bind the container to. If not set defaults to 127.0.0.1")
-	cmd.Flags().StringVar(&o.Registry.Image, "image", registry.DefaultRegistryImageRef,
-		"Registry image to use")
+		"The host's IP address to bind the container to. May be IPv4 or IPv6. If not set defaults to 127.0.0.1")
+	cmd.Flags().BoolVar(&o.Registry.DualStack, "dual-stack", o.Registry.DualStack,
+		"Publish the registry on both IPv4 and IPv6 (e.g., 127.0.0.1 and ::1)")
+	cmd.Flags().StringVar(&o.Registry.Image, "image", o.Registry.Image,
+		"Registry image to use. Defaults to the standard image for the registry flavor")
+	cmd.Flags().StringVar(&o.Registry.Flavor, "flavor", o.Registry.Flavor,
+		fmt.Sprintf("The registry implementation: %s or %s. Defaults to %s",
+			registry.FlavorDistribution, registry.FlavorZot, registry.FlavorDistribution))
+	cmd.Flags().IntVar(&o.Registry.ContainerPort, "container-port", o.Registry.ContainerPort,
+		"The port that the registry listens on inside the container. Defaults to 5000")
+	cmd.Flags().StringVar(&o.StorageVolume, "storage-volume", o.StorageVolume,
+		"A Docker volume to store images in, so that they survive re-creating the registry")
+	cmd.Flags().StringVar(&o.StorageHostPath, "storage-host-path", o.StorageHostPath,
+		"A host directory to store images in, so that they survive re-creating the registry")
+	addProgressFlag(cmd, &o.Progress)
 
 	return cmd
 }
 
 func (o *CreateRegistryOptions) Run(cmd *cobra.Command, args []string) {
+	reporter, flush, err := newProgressReporter(o.Progress, &o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
 	controller, err := registry.DefaultController(o.IOStreams)
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
 		os.Exit(1)
 	}
+	controller.SetProgress(reporter)
 
 	err = o.run(controller, args[0])
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
 		os.Exit(1)
 	}
+	flush()
 }
 
 type registryCreator interface {
@@ -84,9 +113,16 @@ func (o *CreateRegistryOptions) run(controller registryCreator, name string) err
 	defer a.Flush(time.Second)
 
 	o.Registry.Name = name
+	if o.StorageVolume != "" || o.StorageHostPath != "" {
+		o.Registry.Storage = &api.RegistryStorage{
+			Volume:   o.StorageVolume,
+			HostPath: o.StorageHostPath,
+		}
+	}
 	registry.FillDefaults(o.Registry)
 
-	ctx := context.Background()
+	ctx, cancel := signalContext()
+	defer cancel()
 	_, err = controller.Get(ctx, o.Registry.Name)
 	if err == nil {
 		return fmt.Errorf("Cannot create registry: already exists")
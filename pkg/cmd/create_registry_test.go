@@ -25,6 +25,19 @@ func TestCreateRegistry(t *testing.T) {
 	assert.Equal(t, "my-registry", frc.lastRegistry.Name)
 }
 
+func TestCreateRegistryWithStorage(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateRegistryOptions()
+	o.IOStreams = streams
+	o.StorageVolume = "my-registry-data"
+
+	frc := &fakeRegistryController{}
+	err := o.run(frc, "my-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.ctlptl.dev/my-registry created\n", out.String())
+	assert.Equal(t, &api.RegistryStorage{Volume: "my-registry-data"}, frc.lastRegistry.Storage)
+}
+
 type fakeRegistryController struct {
 	lastRegistry *api.Registry
 }
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
 	"github.com/tilt-dev/ctlptl/pkg/registry"
 	"github.com/tilt-dev/ctlptl/pkg/visitor"
 )
@@ -30,8 +33,16 @@ type DeleteOptions struct {
 	// (like what happened with kubectl delete --cascade).
 	Cascade string
 
+	// How to report progress: text or json.
+	Progress string
+
+	progress          progress.Reporter
 	clusterController clusterController
 	registryDeleter   deleter
+
+	// Reads answers to prompts. Shared by all prompts, so that
+	// buffered answers to later prompts aren't lost.
+	in *bufio.Reader
 }
 
 func NewDeleteOptions() *DeleteOptions {
@@ -59,23 +70,41 @@ func (o *DeleteOptions) Command() *cobra.Command {
 	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If the requested object does not exist the command will return exit code 0.")
 	cmd.Flags().StringVar(&o.Cascade, "cascade", "false",
 		"If 'true', objects will be deleted recursively. "+
-			"For example, deleting a cluster will delete any connected registries. Defaults to 'false'.")
+			"For example, deleting a cluster will delete any connected registries, "+
+			"and offer to delete their storage volumes. Defaults to 'false'.")
+	addProgressFlag(cmd, &o.Progress)
 
 	return cmd
 }
 
 func (o *DeleteOptions) Run(cmd *cobra.Command, args []string) {
-	err := o.run(args)
+	reporter, flush, err := newProgressReporter(o.Progress, &o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+	o.progress = reporter
+
+	err = o.run(args)
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		flush()
 		os.Exit(1)
 	}
+	flush()
 }
 
 type deleter interface {
 	Delete(ctx context.Context, name string) error
 }
 
+// Registries that keep their images in a named volume
+// can clean up the volume on cascading deletes.
+type registryVolumeDeleter interface {
+	Get(ctx context.Context, name string) (*api.Registry, error)
+	DeleteVolume(ctx context.Context, volume string) error
+}
+
 type clusterController interface {
 	deleter
 	Get(ctx context.Context, name string) (*api.Cluster, error)
@@ -99,7 +128,8 @@ func (o *DeleteOptions) run(args []string) error {
 		return err
 	}
 
-	ctx := context.TODO()
+	ctx, cancel := signalContext()
+	defer cancel()
 	resources, err = o.cascadeResources(ctx, resources)
 	if err != nil {
 		return err
@@ -143,14 +173,21 @@ func (o *DeleteOptions) run(args []string) error {
 			}
 		case *api.Registry:
 			if o.registryDeleter == nil {
-				o.registryDeleter, err = registry.DefaultController(o.IOStreams)
+				rc, err := registry.DefaultController(o.IOStreams)
 				if err != nil {
 					return err
 				}
+				rc.SetProgress(o.progress)
+				o.registryDeleter = rc
 			}
 
 			registry.FillDefaults(resource)
-			err := o.registryDeleter.Delete(ctx, resource.Name)
+			volume, err := o.registryVolume(ctx, resource.Name)
+			if err != nil {
+				return err
+			}
+
+			err = o.registryDeleter.Delete(ctx, resource.Name)
 			if err != nil {
 				if o.IgnoreNotFound && errors.IsNotFound(err) {
 					continue
@@ -161,6 +198,13 @@ func (o *DeleteOptions) run(args []string) error {
 			if err != nil {
 				return err
 			}
+
+			if volume != "" {
+				err = o.maybeDeleteRegistryVolume(ctx, resource.Name, volume)
+				if err != nil {
+					return err
+				}
+			}
 		default:
 			return fmt.Errorf("cannot delete: %T", resource)
 		}
@@ -217,6 +261,7 @@ func (o *DeleteOptions) getClusterController() (clusterController, error) {
 		if err != nil {
 			return nil, err
 		}
+		controller.SetProgress(o.progress)
 		o.clusterController = controller
 	}
 	return o.clusterController, nil
@@ -272,6 +317,52 @@ func (o *DeleteOptions) cascadeResources(ctx context.Context, resources []runtim
 	return result, nil
 }
 
+// When deleting recursively, find the storage volume of the registry, so that
+// we can offer to delete it after the registry is gone.
+func (o *DeleteOptions) registryVolume(ctx context.Context, name string) (string, error) {
+	if o.Cascade != "true" {
+		return "", nil
+	}
+
+	vd, ok := o.registryDeleter.(registryVolumeDeleter)
+	if !ok {
+		return "", nil
+	}
+
+	r, err := vd.Get(ctx, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if r.Status.Storage == nil {
+		return "", nil
+	}
+	return r.Status.Storage.Volume, nil
+}
+
+// Volumes may hold images that are expensive to rebuild, so ask before deleting.
+func (o *DeleteOptions) maybeDeleteRegistryVolume(ctx context.Context, name, volume string) error {
+	_, _ = fmt.Fprintf(o.ErrOut, "Registry %s stored images in volume %s. Delete the volume? [y/N] ", name, volume)
+	if o.in == nil {
+		o.in = bufio.NewReader(o.In)
+	}
+	answer, _ := o.in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		_, _ = fmt.Fprintf(o.ErrOut, "\nKeeping volume %s\n", volume)
+		return nil
+	}
+
+	err := o.registryDeleter.(registryVolumeDeleter).DeleteVolume(ctx, volume)
+	if err != nil {
+		return fmt.Errorf("deleting registry volume %s: %v", volume, err)
+	}
+	_, _ = fmt.Fprintf(o.ErrOut, "Deleted volume %s\n", volume)
+	return nil
+}
+
 func (o *DeleteOptions) validateCascade() error {
 	if o.Cascade == "" || o.Cascade == "true" || o.Cascade == "false" {
 		return nil
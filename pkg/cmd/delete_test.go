@@ -176,6 +176,88 @@ registry: my-registry
 	assert.Equal(t, "my-registry", rd.lastName)
 }
 
+func TestDeleteCascadeRegistryVolume(t *testing.T) {
+	streams, in, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	rd := &fakeVolumeDeleter{
+		registries: map[string]*api.Registry{
+			"my-registry": &api.Registry{
+				Name: "my-registry",
+				Status: api.RegistryStatus{
+					Storage: &api.RegistryStorage{Volume: "my-registry-data"},
+				},
+			},
+		},
+	}
+	o.registryDeleter = rd
+	o.Cascade = "true"
+	_, _ = in.Write([]byte("y\n"))
+	err := o.run([]string{"registry", "my-registry"})
+	require.NoError(t, err)
+	assert.Equal(t, "registry.ctlptl.dev/my-registry deleted\n", out.String())
+	assert.Equal(t, "my-registry", rd.lastName)
+	assert.Equal(t, "my-registry-data", rd.lastVolume)
+	assert.Contains(t, errOut.String(), "Deleted volume my-registry-data")
+}
+
+func TestDeleteCascadeRegistryVolumes(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	rd := &fakeVolumeDeleter{
+		registries: map[string]*api.Registry{
+			"registry-a": &api.Registry{
+				Name: "registry-a",
+				Status: api.RegistryStatus{
+					Storage: &api.RegistryStorage{Volume: "registry-a-data"},
+				},
+			},
+			"registry-b": &api.Registry{
+				Name: "registry-b",
+				Status: api.RegistryStatus{
+					Storage: &api.RegistryStorage{Volume: "registry-b-data"},
+				},
+			},
+		},
+	}
+	o.registryDeleter = rd
+	o.Cascade = "true"
+
+	// Both answers arrive at once, as they would from a pipe.
+	_, _ = in.Write([]byte("y\ny\n"))
+	err := o.run([]string{"registry", "registry-a", "registry-b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry-a-data", "registry-b-data"}, rd.volumes)
+}
+
+func TestDeleteCascadeRegistryKeepVolume(t *testing.T) {
+	streams, in, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	rd := &fakeVolumeDeleter{
+		registries: map[string]*api.Registry{
+			"my-registry": &api.Registry{
+				Name: "my-registry",
+				Status: api.RegistryStatus{
+					Storage: &api.RegistryStorage{Volume: "my-registry-data"},
+				},
+			},
+		},
+	}
+	o.registryDeleter = rd
+	o.Cascade = "true"
+	_, _ = in.Write([]byte("\n"))
+	err := o.run([]string{"registry", "my-registry"})
+	require.NoError(t, err)
+	assert.Equal(t, "my-registry", rd.lastName)
+	assert.Equal(t, "", rd.lastVolume)
+	assert.Contains(t, errOut.String(), "Keeping volume my-registry-data")
+}
+
 func TestDeleteCascadeInvalid(t *testing.T) {
 	streams, _, _, _ := genericclioptions.NewTestIOStreams()
 	o := NewDeleteOptions()
@@ -200,3 +282,24 @@ func (cd *fakeDeleter) Delete(ctx context.Context, name string) error {
 	cd.lastName = name
 	return nil
 }
+
+type fakeVolumeDeleter struct {
+	fakeDeleter
+	registries map[string]*api.Registry
+	lastVolume string
+	volumes    []string
+}
+
+func (d *fakeVolumeDeleter) Get(ctx context.Context, name string) (*api.Registry, error) {
+	r, ok := d.registries[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "registries"}, name)
+	}
+	return r, nil
+}
+
+func (d *fakeVolumeDeleter) DeleteVolume(ctx context.Context, volume string) error {
+	d.lastVolume = volume
+	d.volumes = append(d.volumes, volume)
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type DescribeOptions struct {
+	genericclioptions.IOStreams
+
+	StartTime time.Time
+}
+
+func NewDescribeOptions() *DescribeOptions {
+	return &DescribeOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+		StartTime: time.Now(),
+	}
+}
+
+func (o *DescribeOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "describe cluster NAME",
+		Short: "Print a detailed report on a cluster",
+		Long: "Print a detailed report on a cluster.\n\n" +
+			"Includes the cluster's nodes and their containers, how the cluster is wired " +
+			"to its registry, any socat port-forwarders to a remote Docker daemon, " +
+			"and recent Warning events from kube-system.",
+		Example: "  ctlptl describe cluster kind-kind",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	return cmd
+}
+
+func (o *DescribeOptions) Run(cmd *cobra.Command, args []string) {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		_, _ = fmt.Fprintf(o.ErrOut, "Unsupported type: %s\n", t)
+		os.Exit(1)
+	}
+
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterDescriber interface {
+	clusterGetter
+	Describe(ctx context.Context, name string) (*cluster.Description, error)
+}
+
+func (o *DescribeOptions) run(controller clusterDescriber, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.describe", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl describe cluster kind' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	desc, err := controller.Describe(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+	return printClusterDescription(o.Out, desc, o.StartTime)
+}
+
+func printClusterDescription(out io.Writer, desc *cluster.Description, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	c := desc.Cluster
+
+	age := "unknown"
+	if !c.Status.CreationTimestamp.IsZero() {
+		age = duration.HumanDuration(now.Sub(c.Status.CreationTimestamp.Time))
+	}
+
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", c.Name)
+	_, _ = fmt.Fprintf(w, "Product:\t%s\n", noneIfEmpty(c.Product))
+	_, _ = fmt.Fprintf(w, "Current:\t%t\n", c.Status.Current)
+	_, _ = fmt.Fprintf(w, "Kubernetes Version:\t%s\n", noneIfEmpty(c.Status.KubernetesVersion))
+	_, _ = fmt.Fprintf(w, "Age:\t%s\n", age)
+	if c.Status.Error != "" {
+		_, _ = fmt.Fprintf(w, "Error:\t%s\n", c.Status.Error)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nNodes:\n")
+	switch {
+	case desc.NodesError != "":
+		_, _ = fmt.Fprintf(w, "  error: %s\n", desc.NodesError)
+	case len(desc.Nodes) == 0:
+		_, _ = fmt.Fprintf(w, "  <none>\n")
+	default:
+		_, _ = fmt.Fprintf(w, "  NAME\tREADY\tROLES\tVERSION\tRUNTIME\tINTERNAL-IP\n")
+		for _, n := range desc.Nodes {
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+				n.Name, n.Ready, noneIfEmpty(strings.Join(n.Roles, ",")),
+				noneIfEmpty(n.KubeletVersion), noneIfEmpty(n.ContainerRuntime), noneIfEmpty(n.InternalIP))
+		}
+	}
+
+	for _, n := range desc.Nodes {
+		if n.Container == nil {
+			continue
+		}
+		ctr := n.Container
+		hosts := noneIfEmpty(strings.Join(ctr.RegistryHosts, ", "))
+		if ctr.RegistryHostsError != "" {
+			hosts = "error: " + ctr.RegistryHostsError
+		}
+		_, _ = fmt.Fprintf(w, "\nNode Container %s:\n", n.Name)
+		_, _ = fmt.Fprintf(w, "  Image:\t%s\n", noneIfEmpty(ctr.Image))
+		_, _ = fmt.Fprintf(w, "  State:\t%s\n", noneIfEmpty(ctr.State))
+		_, _ = fmt.Fprintf(w, "  Networks:\t%s\n", noneIfEmpty(strings.Join(ctr.Networks, ", ")))
+		_, _ = fmt.Fprintf(w, "  Registry Hosts (certs.d):\t%s\n", hosts)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nRegistry:\n")
+	hosting := c.Status.LocalRegistryHosting
+	if c.Registry == "" && hosting == nil {
+		_, _ = fmt.Fprintf(w, "  <none>\n")
+	} else {
+		_, _ = fmt.Fprintf(w, "  Name:\t%s\n", noneIfEmpty(c.Registry))
+		if hosting != nil {
+			_, _ = fmt.Fprintf(w, "  Host:\t%s\n", noneIfEmpty(hosting.Host))
+			_, _ = fmt.Fprintf(w, "  Host From Container Runtime:\t%s\n", noneIfEmpty(hosting.HostFromContainerRuntime))
+			_, _ = fmt.Fprintf(w, "  Host From Cluster Network:\t%s\n", noneIfEmpty(hosting.HostFromClusterNetwork))
+		} else {
+			_, _ = fmt.Fprintf(w, "  LocalRegistryHosting:\t<none>\n")
+		}
+		if desc.RegistryError != "" {
+			_, _ = fmt.Fprintf(w, "  Error:\t%s\n", desc.RegistryError)
+		} else if r := desc.Registry; r != nil {
+			_, _ = fmt.Fprintf(w, "  State:\t%s\n", noneIfEmpty(r.Status.State))
+			_, _ = fmt.Fprintf(w, "  Networks:\t%s\n", noneIfEmpty(strings.Join(r.Status.Networks, ", ")))
+			_, _ = fmt.Fprintf(w, "  Shared Networks:\t%s\n", noneIfEmpty(strings.Join(sharedNetworks(desc), ", ")))
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nForwarders:\n")
+	switch {
+	case !desc.RemoteDocker:
+		_, _ = fmt.Fprintf(w, "  <none> (Docker is local)\n")
+	case desc.ForwardersError != "":
+		_, _ = fmt.Fprintf(w, "  error: %s\n", desc.ForwardersError)
+	case len(desc.Forwarders) == 0:
+		_, _ = fmt.Fprintf(w, "  <none>\n")
+	default:
+		for _, port := range desc.Forwarders {
+			_, _ = fmt.Fprintf(w, "  localhost:%d\n", port)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nEvents:\n")
+	switch {
+	case desc.EventsError != "":
+		_, _ = fmt.Fprintf(w, "  error: %s\n", desc.EventsError)
+	case len(desc.Events) == 0:
+		_, _ = fmt.Fprintf(w, "  <none>\n")
+	default:
+		_, _ = fmt.Fprintf(w, "  LAST SEEN\tREASON\tOBJECT\tMESSAGE\n")
+		for _, e := range desc.Events {
+			lastSeen := "unknown"
+			if t := cluster.EventTime(e); !t.IsZero() {
+				lastSeen = duration.ShortHumanDuration(now.Sub(t))
+			}
+			object := strings.ToLower(e.InvolvedObject.Kind) + "/" + e.InvolvedObject.Name
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n",
+				lastSeen, e.Reason, object, strings.TrimSpace(e.Message))
+		}
+	}
+
+	return w.Flush()
+}
+
+// Networks that the registry shares with at least one node container.
+//
+// A registry that doesn't share a network with the nodes
+// usually can't be reached from the cluster network.
+func sharedNetworks(desc *cluster.Description) []string {
+	if desc.Registry == nil {
+		return nil
+	}
+	result := []string{}
+	for _, n := range desc.Nodes {
+		if n.Container == nil {
+			continue
+		}
+		for _, network := range n.Container.Networks {
+			if slices.Contains(desc.Registry.Status.Networks, network) && !slices.Contains(result, network) {
+				result = append(result, network)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/localregistry-go"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestDescribeCluster(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDescribeOptions()
+	o.IOStreams = streams
+	now := time.Unix(1700000000, 0)
+	o.StartTime = now
+
+	describer := &fakeClusterDescriber{desc: &cluster.Description{
+		Cluster: &api.Cluster{
+			Name:     "kind-kind",
+			Product:  "kind",
+			Registry: "ctlptl-registry",
+			Status: api.ClusterStatus{
+				Current:           true,
+				KubernetesVersion: "v1.27.3",
+				CreationTimestamp: metav1.Time{Time: now.Add(-2 * time.Hour)},
+				LocalRegistryHosting: &localregistry.LocalRegistryHostingV1{
+					Host:                   "localhost:5005",
+					HostFromClusterNetwork: "ctlptl-registry:5000",
+				},
+			},
+		},
+		Nodes: []cluster.NodeDescription{{
+			Name:             "kind-control-plane",
+			Ready:            "True",
+			Roles:            []string{"control-plane"},
+			KubeletVersion:   "v1.27.3",
+			ContainerRuntime: "containerd://1.7.1",
+			InternalIP:       "172.18.0.2",
+			Container: &cluster.NodeContainer{
+				ID:            "abc",
+				Image:         "kindest/node:v1.27.3",
+				State:         "running",
+				Networks:      []string{"kind"},
+				RegistryHosts: []string{"localhost:5005"},
+			},
+		}},
+		Registry: &api.Registry{
+			Name:   "ctlptl-registry",
+			Status: api.RegistryStatus{State: "running", Networks: []string{"bridge", "kind"}},
+		},
+		Events: []corev1.Event{{
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container\n",
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "coredns-1"},
+			LastTimestamp:  metav1.Time{Time: now.Add(-5 * time.Minute)},
+		}},
+	}}
+
+	err := o.run(describer, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", describer.lastName)
+	assert.Equal(t, `Name:                kind-kind
+Product:             kind
+Current:             true
+Kubernetes Version:  v1.27.3
+Age:                 120m
+
+Nodes:
+  NAME                READY  ROLES          VERSION  RUNTIME             INTERNAL-IP
+  kind-control-plane  True   control-plane  v1.27.3  containerd://1.7.1  172.18.0.2
+
+Node Container kind-control-plane:
+  Image:                     kindest/node:v1.27.3
+  State:                     running
+  Networks:                  kind
+  Registry Hosts (certs.d):  localhost:5005
+
+Registry:
+  Name:                         ctlptl-registry
+  Host:                         localhost:5005
+  Host From Container Runtime:  none
+  Host From Cluster Network:    ctlptl-registry:5000
+  State:                        running
+  Networks:                     bridge, kind
+  Shared Networks:              kind
+
+Forwarders:
+  <none> (Docker is local)
+
+Events:
+  LAST SEEN  REASON   OBJECT         MESSAGE
+  5m         BackOff  pod/coredns-1  Back-off restarting failed container
+`, out.String())
+}
+
+func TestDescribeClusterNotFound(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewDescribeOptions()
+	o.IOStreams = streams
+
+	err := o.run(&fakeClusterDescriber{}, "dunkees")
+	if assert.Error(t, err) {
+		assert.True(t, errors.IsNotFound(err))
+	}
+}
+
+type fakeClusterDescriber struct {
+	desc     *cluster.Description
+	lastName string
+}
+
+func (d *fakeClusterDescriber) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	if d.desc == nil || name != d.desc.Cluster.Name {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
+	}
+	return d.desc.Cluster, nil
+}
+
+func (d *fakeClusterDescriber) Describe(ctx context.Context, name string) (*cluster.Description, error) {
+	d.lastName = name
+	return d.desc, nil
+}
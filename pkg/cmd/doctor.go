@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type DoctorOptions struct {
+	genericclioptions.IOStreams
+
+	Products []string
+	Ports    []int
+}
+
+func NewDoctorOptions() *DoctorOptions {
+	return &DoctorOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *DoctorOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check your environment for problems that prevent ctlptl from creating clusters",
+		Long: "Check your environment for problems that prevent ctlptl from creating clusters.\n\n" +
+			"Checks the Docker engine, the kind/k3d/minikube CLIs and their versions, " +
+			"socat and kubectl, Docker's default network, and whether ports are free. " +
+			"Suggests a fix for each problem. Exits with a non-zero code if any check fails.",
+		Example: "  ctlptl doctor\n" +
+			"  ctlptl doctor --product kind --port 5005",
+		Run:  o.Run,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().StringSliceVar(&o.Products, "product", o.Products,
+		"Products that must be installed (e.g., kind, k3d, minikube). Defaults to checking all of them, and only warning if they're missing.")
+	cmd.Flags().IntSliceVar(&o.Ports, "port", o.Ports,
+		"Local ports that must be free, e.g., for a registry")
+
+	return cmd
+}
+
+func (o *DoctorOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type doctor interface {
+	Doctor(ctx context.Context, options cluster.DoctorOptions) []cluster.Check
+}
+
+func (o *DoctorOptions) run(controller doctor) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.doctor", nil)
+	defer a.Flush(time.Second)
+
+	products := []clusterid.Product{}
+	for _, p := range o.Products {
+		products = append(products, clusterid.Product(p))
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	checks := controller.Doctor(ctx, cluster.DoctorOptions{
+		Products: products,
+		Ports:    o.Ports,
+	})
+	failed := printChecks(o.Out, checks)
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
+
+// Prints a pass/warn/fail report. Returns the number of failures.
+func printChecks(w io.Writer, checks []cluster.Check) int {
+	failed := 0
+	for _, check := range checks {
+		if check.Status == cluster.CheckFail {
+			failed++
+		}
+		_, _ = fmt.Fprintf(w, "[%s] %s: %s\n", check.Status, check.Name, check.Message)
+		if check.Fix != "" {
+			_, _ = fmt.Fprintf(w, "       fix: %s\n", check.Fix)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestDoctor(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDoctorOptions()
+	o.IOStreams = streams
+	o.Products = []string{"kind"}
+	o.Ports = []int{5005}
+
+	d := &fakeDoctor{checks: []cluster.Check{
+		{Name: "docker", Status: cluster.CheckPass, Message: "local Docker engine at unix:///var/run/docker.sock"},
+		{Name: "kind", Status: cluster.CheckPass, Message: "kind v0.31.0"},
+		{Name: "kubectl", Status: cluster.CheckWarn, Message: "kubectl not found", Fix: "Install kubectl"},
+	}}
+	err := o.run(d)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.DoctorOptions{
+		Products: []clusterid.Product{clusterid.ProductKIND},
+		Ports:    []int{5005},
+	}, d.lastOptions)
+	assert.Equal(t, `[pass] docker: local Docker engine at unix:///var/run/docker.sock
+[pass] kind: kind v0.31.0
+[warn] kubectl: kubectl not found
+       fix: Install kubectl
+`, out.String())
+}
+
+func TestDoctorFailed(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDoctorOptions()
+	o.IOStreams = streams
+
+	d := &fakeDoctor{checks: []cluster.Check{
+		{Name: "docker", Status: cluster.CheckFail, Message: "Cannot connect to Docker", Fix: "Start Docker"},
+	}}
+	err := o.run(d)
+	if assert.Error(t, err) {
+		assert.Equal(t, "1 checks failed", err.Error())
+	}
+	assert.Equal(t, "[fail] docker: Cannot connect to Docker\n       fix: Start Docker\n", out.String())
+}
+
+type fakeDoctor struct {
+	checks      []cluster.Check
+	lastOptions cluster.DoctorOptions
+}
+
+func (d *fakeDoctor) Doctor(ctx context.Context, options cluster.DoctorOptions) []cluster.Check {
+	d.lastOptions = options
+	return d.checks
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+type GCOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+
+	DryRun bool
+	MaxAge time.Duration
+}
+
+func NewGCOptions() *GCOptions {
+	return &GCOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("deleted"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *GCOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Delete clusters that have expired",
+		Long: "Delete clusters that have expired.\n\n" +
+			"A cluster expires when it outlives the 'ttl' in its config. " +
+			"Clusters without a TTL only expire if you set --max-age. " +
+			"Only kind, k3d, and minikube clusters created by ctlptl expire. " +
+			"Also deletes registries that were only used by expired clusters.",
+		Example: "  ctlptl gc\n" +
+			"  ctlptl gc --dry-run --max-age=72h",
+		Run:  o.Run,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun,
+		"Print the clusters and registries that would be deleted, without deleting them")
+	cmd.Flags().DurationVar(&o.MaxAge, "max-age", o.MaxAge,
+		"Also delete clusters without a TTL that are older than this. If zero, they never expire")
+
+	return cmd
+}
+
+func (o *GCOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterGC interface {
+	GC(ctx context.Context, options cluster.GCOptions) (*cluster.GCResult, error)
+}
+
+func (o *GCOptions) run(controller clusterGC) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.gc", map[string]string{"dryRun": fmt.Sprintf("%v", o.DryRun)})
+	defer a.Flush(time.Second)
+
+	if o.MaxAge < 0 {
+		return fmt.Errorf("invalid --max-age %s: must be positive", o.MaxAge)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	result, err := controller.GC(ctx, cluster.GCOptions{
+		DryRun: o.DryRun,
+		MaxAge: o.MaxAge,
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		o.PrintFlags.Complete("%s (dry run)")
+	}
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Clusters {
+		err := printer.PrintObj(&result.Clusters[i], o.Out)
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range result.Registries {
+		err := printer.PrintObj(&api.Registry{TypeMeta: registry.TypeMeta(), Name: name}, o.Out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestGC(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGCOptions()
+	o.IOStreams = streams
+
+	c := &fakeClusterGC{result: &cluster.GCResult{
+		Clusters:   []api.Cluster{{TypeMeta: cluster.TypeMeta(), Name: "kind-kind"}},
+		Registries: []string{"kind-registry"},
+	}}
+	err := o.run(c)
+	require.NoError(t, err)
+	assert.False(t, c.lastOptions.DryRun)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind deleted\n"+
+		"registry.ctlptl.dev/kind-registry deleted\n", out.String())
+}
+
+func TestGCDryRun(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGCOptions()
+	o.IOStreams = streams
+	o.DryRun = true
+	o.MaxAge = 72 * time.Hour
+
+	c := &fakeClusterGC{result: &cluster.GCResult{
+		Clusters: []api.Cluster{{TypeMeta: cluster.TypeMeta(), Name: "kind-kind"}},
+	}}
+	err := o.run(c)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.GCOptions{DryRun: true, MaxAge: 72 * time.Hour}, c.lastOptions)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind deleted (dry run)\n", out.String())
+}
+
+type fakeClusterGC struct {
+	result      *cluster.GCResult
+	lastOptions cluster.GCOptions
+}
+
+func (c *fakeClusterGC) GC(ctx context.Context, options cluster.GCOptions) (*cluster.GCResult, error) {
+	c.lastOptions = options
+	return c.result, nil
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"net"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -18,6 +21,7 @@ import (
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/config"
 	"github.com/tilt-dev/ctlptl/pkg/registry"
 )
 
@@ -27,6 +31,11 @@ type GetOptions struct {
 	StartTime      time.Time
 	IgnoreNotFound bool
 	FieldSelector  string
+	LabelSelector  string
+	Registry       string
+	SortBy         string
+	NoHeaders      bool
+	HealthTimeout  time.Duration
 }
 
 func NewGetOptions() *GetOptions {
@@ -40,8 +49,9 @@ func NewGetOptions() *GetOptions {
 func (o *GetOptions) Command() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "get [type] [name]",
-		Short: "Read currently running clusters and registries",
-		Long: `Read the status of currently running clusters and registries.
+		Short: "Read currently running clusters, registries, images, and snapshots",
+		Long: `Read the status of currently running clusters and registries,
+the images stored in a registry, or saved cluster snapshots.
 
 Supports the same flags as kubectl for selecting
 and printing fields. The kubectl cheat sheet may help:
@@ -50,7 +60,14 @@ https://kubernetes.io/docs/reference/kubectl/cheatsheet/#formatting-output
 `,
 		Example: "  ctlptl get\n" +
 			"  ctlptl get cluster microk8s -o yaml\n" +
-			"  ctlptl get cluster kind-kind -o template --template '{{.status.localRegistryHosting.host}}'\n",
+			"  ctlptl get clusters -o wide --sort-by .status.kubernetesVersion\n" +
+			"  ctlptl get cluster kind-kind -o template --template '{{.status.localRegistryHosting.host}}'\n" +
+			"  ctlptl get clusters -l team=payments --field-selector status.current=true\n" +
+			"  ctlptl get registries -l app=k3d\n" +
+			"  ctlptl get registries --field-selector status.state!=running\n" +
+			"  ctlptl get images --registry ctlptl-registry\n" +
+			"  ctlptl get image my-app --registry ctlptl-registry -o yaml\n" +
+			"  ctlptl get snapshots\n",
 		Run:  o.Run,
 		Args: cobra.MaximumNArgs(2),
 	}
@@ -60,7 +77,12 @@ https://kubernetes.io/docs/reference/kubectl/cheatsheet/#formatting-output
 	o.AddFlags(cmd)
 
 	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If the requested object does not exist the command will return exit code 0.")
+	cmd.Flags().StringVar(&o.Registry, "registry", o.Registry, "The registry to read images from. Required when getting images.")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort list types using this field specification. The field specification is expressed as a JSONPath expression (e.g. '{.status.creationTimestamp}').")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default or wide output format, don't print headers.")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin', and existence.(e.g. -l key1=value1,key2=value2). Registries are selected by their container labels, clusters by the labels in their config.")
+	cmd.Flags().DurationVar(&o.HealthTimeout, "health-timeout", o.HealthTimeout, "How long to wait for a cluster to respond before reporting it as broken. Overrides timeouts.healthCheck in ~/.ctlptl/config.yaml. Defaults to 3s.")
 
 	return cmd
 }
@@ -74,7 +96,14 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 	a.Incr("cmd.get", nil)
 	defer a.Flush(time.Second)
 
-	ctx := context.TODO()
+	err = o.validate()
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
 	t := "cluster"
 	if len(args) >= 1 {
 		t = args[0]
@@ -87,6 +116,7 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 			_, _ = fmt.Fprintf(o.ErrOut, "Loading controller: %v\n", err)
 			os.Exit(1)
 		}
+		c.SetProbeStatus(true)
 
 		if len(args) >= 2 {
 			resource, err = c.Get(ctx, args[1])
@@ -98,7 +128,10 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 				os.Exit(1)
 			}
 		} else {
-			resource, err = c.List(ctx, registry.ListOptions{FieldSelector: o.FieldSelector})
+			resource, err = c.List(ctx, registry.ListOptions{
+				FieldSelector: o.FieldSelector,
+				LabelSelector: o.LabelSelector,
+			})
 			if err != nil {
 				_, _ = fmt.Fprintf(o.ErrOut, "List registries: %v\n", err)
 				os.Exit(1)
@@ -111,6 +144,7 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 			_, _ = fmt.Fprintf(o.ErrOut, "Loading controller: %v\n", err)
 			os.Exit(1)
 		}
+		c.SetTimeouts(config.Timeouts{HealthCheck: o.HealthTimeout})
 
 		if len(args) >= 2 {
 			resource, err = normalizedGet(ctx, c, args[1])
@@ -122,15 +156,72 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 				os.Exit(1)
 			}
 		} else {
-			resource, err = c.List(ctx, cluster.ListOptions{FieldSelector: o.FieldSelector})
+			resource, err = c.List(ctx, cluster.ListOptions{
+				FieldSelector: o.FieldSelector,
+				LabelSelector: o.LabelSelector,
+			})
 			if err != nil {
 				_, _ = fmt.Fprintf(o.ErrOut, "List clusters: %v\n", err)
 				os.Exit(1)
 			}
 		}
 
+	case "image", "images":
+		if o.Registry == "" {
+			_, _ = fmt.Fprintf(o.ErrOut, "Getting images requires --registry\n")
+			os.Exit(1)
+		}
+
+		c, err := registry.DefaultController(o.IOStreams)
+		if err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "Loading controller: %v\n", err)
+			os.Exit(1)
+		}
+
+		list, err := c.ListImages(ctx, o.Registry)
+		if err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "List images: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) >= 2 {
+			list = filterImages(list, args[1])
+			if len(list.Items) == 0 {
+				if o.IgnoreNotFound {
+					os.Exit(0)
+				}
+				_, _ = fmt.Fprintf(o.ErrOut, "image %q not found in registry %s\n", args[1], o.Registry)
+				os.Exit(1)
+			}
+		}
+		resource = list
+
+	case "snapshot", "snapshots":
+		c, err := cluster.DefaultController(o.IOStreams)
+		if err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "Loading controller: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) >= 2 {
+			resource, err = c.GetSnapshot(ctx, args[1])
+			if err != nil {
+				if errors.IsNotFound(err) && o.IgnoreNotFound {
+					os.Exit(0)
+				}
+				_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			resource, err = c.ListSnapshots(ctx)
+			if err != nil {
+				_, _ = fmt.Fprintf(o.ErrOut, "List snapshots: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
 	default:
-		_, _ = fmt.Fprintf(o.ErrOut, "Unrecognized type: %s. Possible values: cluster, registry.\n", t)
+		_, _ = fmt.Fprintf(o.ErrOut, "Unrecognized type: %s. Possible values: cluster, registry, image, snapshot.\n", t)
 		os.Exit(1)
 	}
 
@@ -141,9 +232,17 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 	}
 }
 
+// Checks the flags that apply to every resource type.
+func (o *GetOptions) validate() error {
+	return validateTimeoutFlags(0, o.HealthTimeout)
+}
+
 func (o *GetOptions) ToPrinter() (printers.ResourcePrinter, error) {
-	if !o.OutputFlagSpecified() {
-		return printers.NewTablePrinter(printers.PrintOptions{}), nil
+	if o.isTableOutput() {
+		return printers.NewTablePrinter(printers.PrintOptions{
+			Wide:      o.isWideOutput(),
+			NoHeaders: o.NoHeaders,
+		}), nil
 	}
 	return o.PrintFlags.ToPrinter()
 }
@@ -159,7 +258,14 @@ func (o *GetOptions) Print(obj runtime.Object) error {
 		return err
 	}
 
-	if !o.OutputFlagSpecified() {
+	if o.SortBy != "" {
+		err = sortList(obj, o.SortBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.isTableOutput() {
 		err = printer.PrintObj(o.toTable(obj), o.Out)
 		if err != nil {
 			return err
@@ -192,6 +298,15 @@ func (o *GetOptions) OutputFlagSpecified() bool {
 	return o.PrintFlags.OutputFlagSpecified != nil && o.PrintFlags.OutputFlagSpecified()
 }
 
+func (o *GetOptions) isWideOutput() bool {
+	return o.OutputFlagSpecified() && o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "wide"
+}
+
+// The default output and -o wide print a table.
+func (o *GetOptions) isTableOutput() bool {
+	return !o.OutputFlagSpecified() || o.isWideOutput()
+}
+
 func (o *GetOptions) toTable(obj runtime.Object) runtime.Object {
 	switch r := obj.(type) {
 	case *api.Registry:
@@ -202,6 +317,14 @@ func (o *GetOptions) toTable(obj runtime.Object) runtime.Object {
 		return o.clustersAsTable([]api.Cluster{*r})
 	case *api.ClusterList:
 		return o.clustersAsTable(r.Items)
+	case *api.RegistryImage:
+		return o.imagesAsTable([]api.RegistryImage{*r})
+	case *api.RegistryImageList:
+		return o.imagesAsTable(r.Items)
+	case *api.Snapshot:
+		return o.snapshotsAsTable([]api.Snapshot{*r})
+	case *api.SnapshotList:
+		return o.snapshotsAsTable(r.Items)
 	default:
 		return obj
 	}
@@ -231,6 +354,26 @@ func (o *GetOptions) clustersAsTable(clusters []api.Cluster) runtime.Object {
 				Name: "Registry",
 				Type: "string",
 			},
+			metav1.TableColumnDefinition{
+				Name:     "Kubernetes Version",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "CPUs",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "Nodes",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "Error",
+				Type:     "string",
+				Priority: 1,
+			},
 		},
 	}
 
@@ -254,6 +397,11 @@ func (o *GetOptions) clustersAsTable(clusters []api.Cluster) runtime.Object {
 			current = "*"
 		}
 
+		version := cluster.Status.KubernetesVersion
+		if version == "" {
+			version = "unknown"
+		}
+
 		table.Rows = append(table.Rows, metav1.TableRow{
 			Cells: []interface{}{
 				current,
@@ -261,6 +409,10 @@ func (o *GetOptions) clustersAsTable(clusters []api.Cluster) runtime.Object {
 				cluster.Product,
 				age,
 				rHost,
+				version,
+				countOrUnknown(cluster.Status.CPUs),
+				countOrUnknown(cluster.Status.Nodes),
+				noneIfEmpty(cluster.Status.Error),
 			},
 		})
 	}
@@ -276,6 +428,10 @@ func (o *GetOptions) registriesAsTable(registries []api.Registry) runtime.Object
 				Name: "Name",
 				Type: "string",
 			},
+			metav1.TableColumnDefinition{
+				Name: "Status",
+				Type: "string",
+			},
 			metav1.TableColumnDefinition{
 				Name: "Host Address",
 				Type: "int",
@@ -288,13 +444,26 @@ func (o *GetOptions) registriesAsTable(registries []api.Registry) runtime.Object
 				Name: "Age",
 				Type: "string",
 			},
+			metav1.TableColumnDefinition{
+				Name:     "Image",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "Networks",
+				Type:     "string",
+				Priority: 1,
+			},
 		},
 	}
 
-	// sort chronologically newest -> oldest to match `docker ps` behavior
-	sort.SliceStable(registries, func(i, j int) bool {
-		return registries[i].Status.CreationTimestamp.After(registries[j].Status.CreationTimestamp.Time)
-	})
+	// sort chronologically newest -> oldest to match `docker ps` behavior,
+	// unless the user asked for a different order.
+	if o.SortBy == "" {
+		sort.SliceStable(registries, func(i, j int) bool {
+			return registries[i].Status.CreationTimestamp.After(registries[j].Status.CreationTimestamp.Time)
+		})
+	}
 
 	for _, registry := range registries {
 		age := "unknown"
@@ -305,20 +474,194 @@ func (o *GetOptions) registriesAsTable(registries []api.Registry) runtime.Object
 
 		hostAddress := "none"
 		if registry.Status.ListenAddress != "" && registry.Status.HostPort != 0 {
-			hostAddress = fmt.Sprintf("%s:%d", registry.Status.ListenAddress, registry.Status.HostPort)
+			hostAddress = net.JoinHostPort(registry.Status.ListenAddress, strconv.Itoa(registry.Status.HostPort))
 		}
 
 		containerAddress := "none"
 		if registry.Status.ContainerPort != 0 && registry.Status.IPAddress != "" {
-			containerAddress = fmt.Sprintf("%s:%d", registry.Status.IPAddress, registry.Status.ContainerPort)
+			containerAddress = net.JoinHostPort(registry.Status.IPAddress, strconv.Itoa(registry.Status.ContainerPort))
+		}
+
+		// Show the container state, unless the container is running,
+		// in which case show whether the registry API is answering.
+		status := registry.Status.State
+		if status == "running" {
+			status = "ready"
+			if !registry.Status.Ready {
+				status = "not ready"
+			}
+		} else if status == "" {
+			status = "unknown"
 		}
 
 		table.Rows = append(table.Rows, metav1.TableRow{
 			Cells: []interface{}{
 				registry.Name,
+				status,
 				hostAddress,
 				containerAddress,
 				age,
+				noneIfEmpty(registry.Status.Image),
+				noneIfEmpty(strings.Join(registry.Status.Networks, ",")),
+			},
+		})
+	}
+
+	return &table
+}
+
+func noneIfEmpty(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func countOrUnknown(n int) string {
+	if n == 0 {
+		return "unknown"
+	}
+	return strconv.Itoa(n)
+}
+
+// Filters images by repository, or by repository and tag.
+func filterImages(list *api.RegistryImageList, ref string) *api.RegistryImageList {
+	repo, tag, hasTag := strings.Cut(ref, ":")
+	result := list.DeepCopy()
+	result.Items = nil
+	for _, image := range list.Items {
+		if image.Repository != repo || (hasTag && image.Tag != tag) {
+			continue
+		}
+		result.Items = append(result.Items, image)
+	}
+	return result
+}
+
+func (o *GetOptions) imagesAsTable(images []api.RegistryImage) runtime.Object {
+	table := metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "metav1.k8s.io"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			metav1.TableColumnDefinition{
+				Name: "Repository",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Tag",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Digest",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Size",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Pushed",
+				Type: "string",
+			},
+		},
+	}
+
+	for _, image := range images {
+		pushed := "unknown"
+		pTime := image.PushTimestamp.Time
+		if !pTime.IsZero() {
+			pushed = duration.ShortHumanDuration(o.StartTime.Sub(pTime)) + " ago"
+		}
+
+		// Abbreviate the digest like `docker images` does.
+		digest := image.Digest
+		algo, hex, ok := strings.Cut(digest, ":")
+		if ok && len(hex) > 12 {
+			digest = fmt.Sprintf("%s:%s", algo, hex[:12])
+		}
+		if digest == "" {
+			digest = "none"
+		}
+
+		size := "unknown"
+		if image.Size > 0 {
+			size = units.HumanSize(float64(image.Size))
+		}
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				image.Repository,
+				image.Tag,
+				digest,
+				size,
+				pushed,
+			},
+		})
+	}
+
+	return &table
+}
+
+func (o *GetOptions) snapshotsAsTable(snapshots []api.Snapshot) runtime.Object {
+	table := metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "metav1.k8s.io"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			metav1.TableColumnDefinition{
+				Name: "Name",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Cluster",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Registry",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Nodes",
+				Type: "integer",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Size",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Age",
+				Type: "string",
+			},
+		},
+	}
+
+	for _, snapshot := range snapshots {
+		age := "unknown"
+		cTime := snapshot.CreationTimestamp.Time
+		if !cTime.IsZero() {
+			age = duration.ShortHumanDuration(o.StartTime.Sub(cTime))
+		}
+
+		registry := "none"
+		if snapshot.Spec != nil && snapshot.Spec.Registry != "" {
+			registry = snapshot.Spec.Registry
+		}
+
+		// Node images share their base layers, so the total is an upper bound.
+		var total int64
+		for _, node := range snapshot.Nodes {
+			total += node.Size
+		}
+		size := "unknown"
+		if total > 0 {
+			size = units.HumanSize(float64(total))
+		}
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				snapshot.Name,
+				snapshot.Cluster,
+				registry,
+				len(snapshot.Nodes),
+				size,
+				age,
 			},
 		})
 	}
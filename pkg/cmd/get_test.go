@@ -60,6 +60,8 @@ var registryList = &api.RegistryList{
 				ListenAddress:     "0.0.0.0",
 				ContainerPort:     5000,
 				HostPort:          5001,
+				State:             "running",
+				Ready:             true,
 			},
 		},
 		api.Registry{
@@ -73,6 +75,8 @@ var registryList = &api.RegistryList{
 				ListenAddress:     "127.0.0.1",
 				ContainerPort:     5000,
 				HostPort:          5002,
+				State:             "running",
+				LastProbeError:    "connection refused",
 			},
 		},
 	},
@@ -132,8 +136,181 @@ func TestRegistryPrint(t *testing.T) {
 
 	err := o.Print(o.toTable(registryList))
 	require.NoError(t, err)
-	assert.Equal(t, `NAME                       HOST ADDRESS     CONTAINER ADDRESS   AGE
-ctlptl-registry            0.0.0.0:5001     172.17.0.2:5000     3y
-ctlptl-registry-loopback   127.0.0.1:5002   172.17.0.3:5000     3y
+	assert.Equal(t, `NAME                       STATUS      HOST ADDRESS     CONTAINER ADDRESS   AGE
+ctlptl-registry            ready       0.0.0.0:5001     172.17.0.2:5000     3y
+ctlptl-registry-loopback   not ready   127.0.0.1:5002   172.17.0.3:5000     3y
+`, out.String())
+}
+
+func TestRegistryPrintIPv6(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+	o.NoHeaders = true
+
+	list := registryList.DeepCopy()
+	list.Items = list.Items[1:]
+	list.Items[0].Status.ListenAddress = "::1"
+
+	err := o.Print(o.toTable(list))
+	require.NoError(t, err)
+	assert.Equal(t, "ctlptl-registry-loopback   not ready   [::1]:5002   172.17.0.3:5000   3y\n", out.String())
+}
+
+var imageList = &api.RegistryImageList{
+	Items: []api.RegistryImage{
+		api.RegistryImage{
+			Registry:      "ctlptl-registry",
+			Repository:    "my-app",
+			Tag:           "latest",
+			Digest:        "sha256:2d4f4b5309b1e41b4f83ae59b44df6d673ef44433c734b14c1c103ebca82c116",
+			Size:          12345678,
+			PushTimestamp: metav1.Time{Time: startTime.Add(-5 * time.Minute)},
+		},
+		api.RegistryImage{
+			Registry:   "ctlptl-registry",
+			Repository: "other",
+			Tag:        "v1",
+		},
+	},
+}
+
+func TestImagePrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	err := o.Print(o.toTable(imageList))
+	require.NoError(t, err)
+	assert.Equal(t, `REPOSITORY   TAG      DIGEST                SIZE      PUSHED
+my-app       latest   sha256:2d4f4b5309b1   12.35MB   5m ago
+other        v1       none                  unknown   unknown
+`, out.String())
+}
+
+func TestFilterImages(t *testing.T) {
+	assert.Len(t, filterImages(imageList, "my-app").Items, 1)
+	assert.Len(t, filterImages(imageList, "my-app:latest").Items, 1)
+	assert.Len(t, filterImages(imageList, "my-app:v1").Items, 0)
+	assert.Len(t, filterImages(imageList, "nope").Items, 0)
+}
+
+func TestWidePrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	err := o.Command().Flags().Set("output", "wide")
+	require.NoError(t, err)
+
+	list := clusterList.DeepCopy()
+	list.Items[0].Status.KubernetesVersion = "v1.30.0"
+	list.Items[0].Status.CPUs = 4
+	list.Items[0].Status.Nodes = 1
+	list.Items[1].Status.Error = "healthcheck: connection refused"
+
+	err = o.Print(list)
+	require.NoError(t, err)
+	assert.Equal(t, `CURRENT   NAME        PRODUCT    AGE   REGISTRY         KUBERNETES VERSION   CPUS      NODES     ERROR
+*         microk8s    microk8s   3y    none             v1.30.0              4         1         none
+          kind-kind   KIND       3y    localhost:5000   unknown              unknown   unknown   healthcheck: connection refused
+`, out.String())
+}
+
+func TestRegistryWidePrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+	o.NoHeaders = true
+
+	err := o.Command().Flags().Set("output", "wide")
+	require.NoError(t, err)
+
+	list := registryList.DeepCopy()
+	list.Items[0].Status.Image = "docker.io/library/registry:2"
+	list.Items[0].Status.Networks = []string{"bridge", "kind"}
+
+	err = o.Print(list)
+	require.NoError(t, err)
+	assert.Equal(t, `ctlptl-registry            ready       0.0.0.0:5001     172.17.0.2:5000   3y    docker.io/library/registry:2   bridge,kind
+ctlptl-registry-loopback   not ready   127.0.0.1:5002   172.17.0.3:5000   3y    none                           none
+`, out.String())
+}
+
+func TestSortBy(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+	o.SortBy = "name"
+
+	err := o.Print(clusterList.DeepCopy())
+	require.NoError(t, err)
+	assert.Equal(t, `CURRENT   NAME        PRODUCT    AGE   REGISTRY
+          kind-kind   KIND       3y    localhost:5000
+*         microk8s    microk8s   3y    none
+`, out.String())
+
+	// Registries sort newest first by default, but --sort-by takes precedence.
+	out.Reset()
+	o.SortBy = "{.status.hostPort}"
+	list := registryList.DeepCopy()
+	list.Items[0].Status.HostPort = 5003
+	err = o.Print(list)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ctlptl-registry-loopback", "ctlptl-registry"},
+		[]string{list.Items[0].Name, list.Items[1].Name})
+}
+
+func TestSortByInvalid(t *testing.T) {
+	o := NewGetOptions()
+	o.SortBy = "{.name"
+	err := o.Print(clusterList.DeepCopy())
+	assert.Error(t, err)
+}
+
+func TestGetNegativeHealthTimeout(t *testing.T) {
+	o := NewGetOptions()
+	err := o.Command().Flags().Set("health-timeout", "-3s")
+	require.NoError(t, err)
+
+	err = o.validate()
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid --health-timeout -3s: must be a positive duration, like 3s", err.Error())
+	}
+}
+
+func TestSnapshotPrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	err := o.Print(o.toTable(&api.SnapshotList{
+		Items: []api.Snapshot{
+			api.Snapshot{
+				Name:              "base",
+				Cluster:           "kind-kind",
+				Spec:              &api.Cluster{Registry: "ctlptl-registry"},
+				CreationTimestamp: metav1.Time{Time: startTime.Add(-time.Hour)},
+				Nodes: []api.SnapshotNode{
+					{Name: "kind-control-plane", Size: 1000000000},
+					{Name: "kind-worker", Size: 500000000},
+				},
+			},
+			api.Snapshot{
+				Name:    "empty",
+				Cluster: "kind-other",
+			},
+		},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, `NAME    CLUSTER      REGISTRY          NODES   SIZE      AGE
+base    kind-kind    ctlptl-registry   2       1.5GB     1h
+empty   kind-other   none              0       unknown   unknown
 `, out.String())
 }
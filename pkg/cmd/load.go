@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func NewLoadCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "load",
+		Short: "Copy local resources into a cluster",
+	}
+
+	cmd.AddCommand(NewLoadImageOptions().Command())
+
+	return cmd
+}
+
+type LoadImageOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+}
+
+func NewLoadImageOptions() *LoadImageOptions {
+	return &LoadImageOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("images loaded"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *LoadImageOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "image CLUSTER IMAGE...",
+		Short: "Copy images from the local Docker daemon into every node of a cluster",
+		Long: "Copy images from the local Docker daemon into every node of a cluster.\n\n" +
+			"Pods can then use the images without pulling them. " +
+			"Pulls any images that aren't on the local Docker daemon yet. " +
+			"Supported on kind, k3d, and minikube clusters.\n\n" +
+			"To load images whenever a cluster is created, list them under 'images:' in the cluster config.",
+		Example: "  ctlptl load image kind-kind postgres:16 redis:7",
+		Run:     o.Run,
+		Args:    cobra.MinimumNArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *LoadImageOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[0], args[1:])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type imageLoader interface {
+	clusterGetter
+	LoadImages(ctx context.Context, name string, images []string) (*api.Cluster, error)
+}
+
+func (o *LoadImageOptions) run(controller imageLoader, name string, images []string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.load.image", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl load image kind IMAGE' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	c, err = controller.LoadImages(ctx, c.Name, images)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(c, o.Out)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestLoadImage(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewLoadImageOptions()
+	o.IOStreams = streams
+
+	c := &fakeImageLoader{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "kind", []string{"postgres:16", "redis:7"})
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", c.lastCluster)
+	assert.Equal(t, []string{"postgres:16", "redis:7"}, c.lastImages)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind images loaded\n", out.String())
+}
+
+type fakeImageLoader struct {
+	clusters    map[string]*api.Cluster
+	lastCluster string
+	lastImages  []string
+}
+
+func (c *fakeImageLoader) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, ok := c.clusters[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
+	}
+	return cluster, nil
+}
+
+func (c *fakeImageLoader) LoadImages(ctx context.Context, name string, images []string) (*api.Cluster, error) {
+	c.lastCluster = name
+	c.lastImages = images
+	return c.clusters[name], nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Prefixes each line of output, so that output from clusters
+// applied in parallel can be told apart.
+//
+// Writers that share an output should share a lock, so that
+// their lines don't interleave.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(mu *sync.Mutex, out io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{mu: mu, out: out, prefix: prefix}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			// Keep the partial line until the rest of it arrives.
+			w.buf.Reset()
+			w.buf.Write(line)
+			break
+		}
+		_, err = w.out.Write(append([]byte(w.prefix), line...))
+		if err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Writes any output that didn't end in a newline.
+func (w *prefixWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.out.Write(append([]byte(w.prefix), append(w.buf.Bytes(), '\n')...))
+	w.buf.Reset()
+	return err
+}
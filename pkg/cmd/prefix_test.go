@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	var mu sync.Mutex
+	a := newPrefixWriter(&mu, out, "[kind-a] ")
+	b := newPrefixWriter(&mu, out, "[kind-b] ")
+
+	_, _ = fmt.Fprint(a, "Creating ")
+	_, _ = fmt.Fprint(b, "Creating cluster\n")
+	_, _ = fmt.Fprint(a, "cluster\nDone")
+	require.NoError(t, a.Flush())
+	require.NoError(t, b.Flush())
+
+	assert.Equal(t,
+		"[kind-b] Creating cluster\n"+
+			"[kind-a] Creating cluster\n"+
+			"[kind-a] Done\n",
+		out.String())
+}
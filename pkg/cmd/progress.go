@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/progress"
+)
+
+func addProgressFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVar(format, "progress", "text",
+		"How to report progress on stderr: text, or json for one event per line with timestamps and phase durations (for CI)")
+}
+
+// Sets up the progress reporter for the given --progress format.
+//
+// In json format, the reporter replaces streams.ErrOut, so that
+// progress text (and the final error) becomes message events.
+// Call the returned func before the command exits, to emit any text
+// that didn't end in a newline.
+func newProgressReporter(format string, streams *genericclioptions.IOStreams) (progress.Reporter, func(), error) {
+	switch format {
+	case "", "text":
+		return progress.Text, func() {}, nil
+	case "json":
+		r := progress.NewJSONReporter(streams.ErrOut)
+		streams.ErrOut = r
+		return r, func() { _ = r.Flush() }, nil
+	}
+	return nil, nil, fmt.Errorf("invalid --progress=%s: must be text or json", format)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/progress"
+)
+
+func TestProgressReporterText(t *testing.T) {
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	r, flush, err := newProgressReporter("text", &streams)
+	require.NoError(t, err)
+	assert.Equal(t, progress.Text, r)
+
+	_, _ = fmt.Fprintln(streams.ErrOut, "Creating cluster")
+	flush()
+	assert.Equal(t, "Creating cluster\n", errOut.String())
+}
+
+func TestProgressReporterJSON(t *testing.T) {
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	r, flush, err := newProgressReporter("json", &streams)
+	require.NoError(t, err)
+
+	r.Start(progress.PhaseCreate, "kind-kind")(nil)
+	_, _ = fmt.Fprint(streams.ErrOut, "cluster not found")
+	flush()
+
+	lines := bytes.Split(bytes.TrimSpace(errOut.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), `"type":"phase-start","phase":"create","name":"kind-kind"`)
+	assert.Contains(t, string(lines[1]), `"type":"phase-end","phase":"create"`)
+	assert.Contains(t, string(lines[2]), `"type":"message","message":"cluster not found"`)
+}
+
+func TestProgressReporterInvalid(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	_, _, err := newProgressReporter("yaml", &streams)
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid --progress=yaml: must be text or json", err.Error())
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type PruneOptions struct {
+	genericclioptions.IOStreams
+
+	Yes bool
+}
+
+func NewPruneOptions() *PruneOptions {
+	return &PruneOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *PruneOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete resources left behind by failed creates and manual deletes",
+		Long: "Delete resources left behind by failed creates and manual deletes.\n\n" +
+			"Finds stopped clusters that have containers but no kubeconfig context, " +
+			"kubeconfig contexts of kind and k3d clusters whose containers are gone, " +
+			"registries that no cluster is connected to, " +
+			"and port-forwarders for remote Docker daemons that no longer forward anything. " +
+			"Prints what it found, and asks before deleting it.",
+		Example: "  ctlptl prune\n" +
+			"  ctlptl prune --yes",
+		Run:  o.Run,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", o.Yes, "Delete without asking for confirmation")
+
+	return cmd
+}
+
+func (o *PruneOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type pruner interface {
+	FindOrphans(ctx context.Context) (*cluster.Orphans, error)
+	Prune(ctx context.Context, orphans *cluster.Orphans) error
+}
+
+func (o *PruneOptions) run(controller pruner) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.prune", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	orphans, err := controller.FindOrphans(ctx)
+	if err != nil {
+		return err
+	}
+
+	if orphans.Empty() {
+		_, _ = fmt.Fprintln(o.Out, "Nothing to prune")
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(o.Out, "Found resources to prune:")
+	for _, c := range orphans.Clusters {
+		_, _ = fmt.Fprintf(o.Out, "  cluster %s: stopped %s cluster with no kubeconfig context\n", c.Name, c.Product)
+	}
+	for _, name := range orphans.Contexts {
+		_, _ = fmt.Fprintf(o.Out, "  context %s: cluster containers are gone\n", name)
+	}
+	for _, name := range orphans.Registries {
+		_, _ = fmt.Fprintf(o.Out, "  registry %s: no cluster is connected to it\n", name)
+	}
+	for _, name := range orphans.Portforwarders {
+		_, _ = fmt.Fprintf(o.Out, "  container %s: not running\n", name)
+	}
+	for _, port := range orphans.Forwarders {
+		_, _ = fmt.Fprintf(o.Out, "  socat forwarder on port %d: no cluster or registry uses it\n", port)
+	}
+
+	if !o.Yes {
+		_, _ = fmt.Fprintf(o.ErrOut, "Delete them? [y/N] ")
+		answer, _ := bufio.NewReader(o.In).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			_, _ = fmt.Fprintln(o.ErrOut, "\nNothing deleted")
+			return nil
+		}
+	}
+
+	err = controller.Prune(ctx, orphans)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(o.Out, "Pruned")
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestPrune(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewPruneOptions()
+	o.IOStreams = streams
+	in.WriteString("y\n")
+
+	p := &fakePruner{orphans: &cluster.Orphans{
+		Clusters:   []api.Cluster{{Name: "kind-old", Product: "kind"}},
+		Registries: []string{"old-registry"},
+		Forwarders: []int{5005},
+	}}
+	err := o.run(p)
+	require.NoError(t, err)
+	assert.True(t, p.pruned)
+	assert.Equal(t, `Found resources to prune:
+  cluster kind-old: stopped kind cluster with no kubeconfig context
+  registry old-registry: no cluster is connected to it
+  socat forwarder on port 5005: no cluster or registry uses it
+Pruned
+`, out.String())
+}
+
+func TestPruneDeclined(t *testing.T) {
+	streams, in, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewPruneOptions()
+	o.IOStreams = streams
+	in.WriteString("n\n")
+
+	p := &fakePruner{orphans: &cluster.Orphans{Contexts: []string{"kind-dead"}}}
+	err := o.run(p)
+	require.NoError(t, err)
+	assert.False(t, p.pruned)
+	assert.Contains(t, errOut.String(), "Nothing deleted")
+}
+
+func TestPruneNothing(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewPruneOptions()
+	o.IOStreams = streams
+
+	p := &fakePruner{orphans: &cluster.Orphans{}}
+	err := o.run(p)
+	require.NoError(t, err)
+	assert.False(t, p.pruned)
+	assert.Equal(t, "Nothing to prune\n", out.String())
+}
+
+type fakePruner struct {
+	orphans *cluster.Orphans
+	pruned  bool
+}
+
+func (p *fakePruner) FindOrphans(ctx context.Context) (*cluster.Orphans, error) {
+	return p.orphans, nil
+}
+
+func (p *fakePruner) Prune(ctx context.Context, orphans *cluster.Orphans) error {
+	p.pruned = true
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+func NewRegistryCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "registry",
+		Short: "Maintain image registries",
+	}
+
+	cmd.AddCommand(NewRegistryGCOptions().Command())
+
+	return cmd
+}
+
+type RegistryGCOptions struct {
+	genericclioptions.IOStreams
+
+	OlderThanDays int
+	KeepLast      int
+}
+
+func NewRegistryGCOptions() *RegistryGCOptions {
+	return &RegistryGCOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *RegistryGCOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "gc [name]",
+		Short: "Delete old images and reclaim disk space in a registry",
+		Long: "Delete old images and reclaim disk space in a registry.\n\n" +
+			"Optionally deletes tags through the registry API, then runs the registry's " +
+			"garbage collector to delete any blobs that are no longer referenced.\n\n" +
+			"Images pushed while garbage collection is running may be corrupted. " +
+			"Don't push to the registry until gc finishes.",
+		Example: "  ctlptl registry gc ctlptl-registry\n" +
+			"  ctlptl registry gc ctlptl-registry --older-than-days=7\n" +
+			"  ctlptl registry gc ctlptl-registry --keep-last=3",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.Flags().IntVar(&o.OlderThanDays, "older-than-days", o.OlderThanDays,
+		"Delete tags of images created more than this many days ago")
+	cmd.Flags().IntVar(&o.KeepLast, "keep-last", o.KeepLast,
+		"Keep only this many of the most recently created tags in each repository")
+
+	return cmd
+}
+
+func (o *RegistryGCOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := registry.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type registryGCer interface {
+	GC(ctx context.Context, name string, options registry.GCOptions) (*registry.GCResult, error)
+}
+
+func (o *RegistryGCOptions) run(controller registryGCer, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.registry.gc", nil)
+	defer a.Flush(time.Second)
+
+	if o.OlderThanDays < 0 {
+		return fmt.Errorf("--older-than-days must not be negative")
+	}
+	if o.KeepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	result, err := controller.GC(ctx, name, registry.GCOptions{
+		OlderThan: time.Duration(o.OlderThanDays) * 24 * time.Hour,
+		KeepLast:  o.KeepLast,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Background {
+		_, _ = fmt.Fprintf(o.Out, "Registry %s: deleted %d tags, space will be reclaimed by the registry's garbage collector\n",
+			name, len(result.DeletedTags))
+		return nil
+	}
+	_, _ = fmt.Fprintf(o.Out, "Registry %s: deleted %d tags, reclaimed %s\n",
+		name, len(result.DeletedTags), units.HumanSize(float64(result.BytesReclaimed())))
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/registry"
+)
+
+func TestRegistryGC(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewRegistryGCOptions()
+	o.IOStreams = streams
+	o.OlderThanDays = 7
+	o.KeepLast = 3
+
+	gc := &fakeRegistryGCer{result: &registry.GCResult{
+		DeletedTags: []string{"app:v1", "app:v2"},
+		BytesBefore: 3000000,
+		BytesAfter:  1000000,
+	}}
+	err := o.run(gc, "my-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "my-registry", gc.lastName)
+	assert.Equal(t, registry.GCOptions{OlderThan: 7 * 24 * time.Hour, KeepLast: 3}, gc.lastOptions)
+	assert.Equal(t, "Registry my-registry: deleted 2 tags, reclaimed 2MB\n", out.String())
+}
+
+func TestRegistryGCNegative(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewRegistryGCOptions()
+	o.IOStreams = streams
+	o.KeepLast = -1
+
+	err := o.run(&fakeRegistryGCer{}, "my-registry")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--keep-last")
+}
+
+type fakeRegistryGCer struct {
+	result      *registry.GCResult
+	lastName    string
+	lastOptions registry.GCOptions
+}
+
+func (f *fakeRegistryGCer) GC(ctx context.Context, name string, options registry.GCOptions) (*registry.GCResult, error) {
+	f.lastName = name
+	f.lastOptions = options
+	return f.result, nil
+}
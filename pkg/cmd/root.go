@@ -18,10 +18,21 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.AddCommand(NewGetOptions().Command())
 	rootCmd.AddCommand(NewApplyOptions().Command())
 	rootCmd.AddCommand(NewDeleteOptions().Command())
+	rootCmd.AddCommand(NewStopOptions().Command())
+	rootCmd.AddCommand(NewStartOptions().Command())
+	rootCmd.AddCommand(NewGCOptions().Command())
+	rootCmd.AddCommand(NewPruneOptions().Command())
+	rootCmd.AddCommand(NewDescribeOptions().Command())
+	rootCmd.AddCommand(NewDoctorOptions().Command())
+	rootCmd.AddCommand(NewVerifyOptions().Command())
 	rootCmd.AddCommand(NewDockerDesktopCommand())
 	rootCmd.AddCommand(newDocsCommand(rootCmd))
 	rootCmd.AddCommand(analytics.NewCommand())
 	rootCmd.AddCommand(NewSocatCommand())
+	rootCmd.AddCommand(NewRegistryCommand())
+	rootCmd.AddCommand(NewSnapshotCommand())
+	rootCmd.AddCommand(NewLoadCommand())
+	rootCmd.AddCommand(NewWaitCommand())
 
 	return rootCmd
 }
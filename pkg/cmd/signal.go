@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Returns a context that's cancelled when ctlptl is interrupted (Ctrl-C) or
+// terminated. Cancelling the context interrupts any subprocesses,
+// like `kind create`, and rolls back half-created clusters.
+//
+// After the first signal, the default handlers come back, so a second
+// Ctrl-C exits immediately.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
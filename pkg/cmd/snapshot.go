@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func NewSnapshotCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save clusters as local images, and re-create them later",
+		Long: "Save clusters as local images, and re-create them later.\n\n" +
+			"Restoring a snapshot is much faster than creating a cluster and re-installing " +
+			"everything on it. Currently only supported for kind clusters.\n\n" +
+			"List snapshots with 'ctlptl get snapshots'.",
+	}
+
+	cmd.AddCommand(NewSnapshotCreateOptions().Command())
+	cmd.AddCommand(NewSnapshotRestoreOptions().Command())
+
+	return cmd
+}
+
+type SnapshotCreateOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+}
+
+func NewSnapshotCreateOptions() *SnapshotCreateOptions {
+	return &SnapshotCreateOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *SnapshotCreateOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "create CLUSTER NAME",
+		Short: "Save the nodes of a cluster as local images",
+		Long: "Save the nodes of a cluster as local images.\n\n" +
+			"Stops the cluster, commits each node container (including its /var volume) " +
+			"to an image, then starts the cluster again. " +
+			"Records the cluster config and registry, so that the snapshot can be restored " +
+			"with 'ctlptl snapshot restore'.",
+		Example: "  ctlptl snapshot create kind-kind with-operators",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *SnapshotCreateOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[0], args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type snapshotCreator interface {
+	clusterGetter
+	CreateSnapshot(ctx context.Context, clusterName, name string) (*api.Snapshot, error)
+}
+
+func (o *SnapshotCreateOptions) run(controller snapshotCreator, clusterName, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.snapshot.create", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl snapshot create kind NAME' works.
+	c, err := normalizedGet(ctx, controller, clusterName)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := controller.CreateSnapshot(ctx, c.Name, name)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(snapshot, o.Out)
+}
+
+type SnapshotRestoreOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+}
+
+func NewSnapshotRestoreOptions() *SnapshotRestoreOptions {
+	return &SnapshotRestoreOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("restored"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *SnapshotRestoreOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "restore NAME",
+		Short: "Re-create a cluster from a snapshot",
+		Long: "Re-create a cluster from a snapshot.\n\n" +
+			"Creates the cluster that the snapshot was taken from, starting each node from its " +
+			"snapshot image, and re-connects it to its registry. " +
+			"The cluster must not exist; delete it first to reset it.",
+		Example: "  ctlptl delete cluster kind-kind && ctlptl snapshot restore with-operators",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *SnapshotRestoreOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type snapshotRestorer interface {
+	RestoreSnapshot(ctx context.Context, name string) (*api.Cluster, error)
+}
+
+func (o *SnapshotRestoreOptions) run(controller snapshotRestorer, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.snapshot.restore", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	c, err := controller.RestoreSnapshot(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(c, o.Out)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestSnapshotCreate(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewSnapshotCreateOptions()
+	o.IOStreams = streams
+
+	c := &fakeSnapshotController{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "kind", "base")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind/base", c.lastCreate)
+	assert.Equal(t, "snapshot.ctlptl.dev/base created\n", out.String())
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewSnapshotRestoreOptions()
+	o.IOStreams = streams
+
+	c := &fakeSnapshotController{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "base")
+	require.NoError(t, err)
+	assert.Equal(t, "base", c.lastRestore)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind restored\n", out.String())
+}
+
+type fakeSnapshotController struct {
+	clusters    map[string]*api.Cluster
+	lastCreate  string
+	lastRestore string
+}
+
+func (c *fakeSnapshotController) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, ok := c.clusters[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
+	}
+	return cluster, nil
+}
+
+func (c *fakeSnapshotController) CreateSnapshot(ctx context.Context, clusterName, name string) (*api.Snapshot, error) {
+	c.lastCreate = clusterName + "/" + name
+	return &api.Snapshot{
+		TypeMeta: api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "Snapshot"},
+		Name:     name,
+		Cluster:  clusterName,
+	}, nil
+}
+
+func (c *fakeSnapshotController) RestoreSnapshot(ctx context.Context, name string) (*api.Cluster, error) {
+	c.lastRestore = name
+	return c.clusters["kind-kind"], nil
+}
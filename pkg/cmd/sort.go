@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Sorts the items of a list by the field at a JSONPath, like `kubectl get --sort-by`.
+//
+// Items that don't have the field sort first.
+func sortList(obj runtime.Object, sortBy string) error {
+	if !meta.IsListType(obj) {
+		return nil
+	}
+
+	parser := jsonpath.New("sort-by").AllowMissingKeys(true)
+	err := parser.Parse(relaxedJSONPath(sortBy))
+	if err != nil {
+		return fmt.Errorf("--sort-by %q: %v", sortBy, err)
+	}
+
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return err
+	}
+
+	// ExtractList returns pointers into the list, so copy the
+	// items before we shuffle them around.
+	type sortItem struct {
+		obj   runtime.Object
+		value interface{}
+	}
+	sortItems := make([]sortItem, 0, len(items))
+	for _, item := range items {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(item)
+		if err != nil {
+			return err
+		}
+		results, err := parser.FindResults(u)
+		if err != nil {
+			return fmt.Errorf("--sort-by %q: %v", sortBy, err)
+		}
+
+		var value interface{}
+		if len(results) > 0 && len(results[0]) > 0 {
+			v := results[0][0]
+			for v.Kind() == reflect.Interface && !v.IsNil() {
+				v = v.Elem()
+			}
+			if v.IsValid() && v.CanInterface() {
+				value = v.Interface()
+			}
+		}
+		sortItems = append(sortItems, sortItem{obj: item.DeepCopyObject(), value: value})
+	}
+
+	sort.SliceStable(sortItems, func(i, j int) bool {
+		return lessSortValue(sortItems[i].value, sortItems[j].value)
+	})
+
+	sorted := make([]runtime.Object, 0, len(sortItems))
+	for _, item := range sortItems {
+		sorted = append(sorted, item.obj)
+	}
+	return meta.SetList(obj, sorted)
+}
+
+// Accepts the same shorthands as kubectl, e.g.,
+// "{.status.cpus}", ".status.cpus", and "status.cpus".
+func relaxedJSONPath(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	return fmt.Sprintf("{.%s}", strings.TrimPrefix(path, "."))
+}
+
+func lessSortValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return av < bv
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return !av && bv
+		}
+	}
+
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		return af < bf
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
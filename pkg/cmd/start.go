@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type StartOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+}
+
+func NewStartOptions() *StartOptions {
+	return &StartOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("started"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *StartOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "start cluster NAME",
+		Short: "Start a cluster that was stopped with 'ctlptl stop'",
+		Long: "Start a cluster that was stopped with 'ctlptl stop'.\n\n" +
+			"Starts the containers or VM running the cluster, and its registry, " +
+			"then waits for the cluster to be healthy.",
+		Example: "  ctlptl start cluster kind-kind",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *StartOptions) Run(cmd *cobra.Command, args []string) {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		_, _ = fmt.Fprintf(o.ErrOut, "Unsupported type: %s\n", t)
+		os.Exit(1)
+	}
+
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterStarter interface {
+	clusterGetter
+	Start(ctx context.Context, name string) (*api.Cluster, error)
+}
+
+func (o *StartOptions) run(controller clusterStarter, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.start", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl start cluster kind' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	c, err = controller.Start(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(c, o.Out)
+}
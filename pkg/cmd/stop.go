@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type StopOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+}
+
+func NewStopOptions() *StopOptions {
+	return &StopOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("stopped"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *StopOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "stop cluster NAME",
+		Short: "Stop a cluster without deleting it",
+		Long: "Stop a cluster without deleting it.\n\n" +
+			"Stops the containers or VM running the cluster, so that it stops using CPU and memory. " +
+			"Also stops the cluster's registry, unless another running cluster uses it. " +
+			"Use 'ctlptl start cluster' to bring it back.",
+		Example: "  ctlptl stop cluster kind-kind",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *StopOptions) Run(cmd *cobra.Command, args []string) {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		_, _ = fmt.Fprintf(o.ErrOut, "Unsupported type: %s\n", t)
+		os.Exit(1)
+	}
+
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterStopper interface {
+	clusterGetter
+	Stop(ctx context.Context, name string) error
+}
+
+func (o *StopOptions) run(controller clusterStopper, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.stop", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl stop cluster kind' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	err = controller.Stop(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(c, o.Out)
+}
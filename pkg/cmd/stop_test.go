@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestStopCluster(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewStopOptions()
+	o.IOStreams = streams
+
+	c := &fakeClusterStopper{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", c.lastStop)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind stopped\n", out.String())
+}
+
+func TestStartCluster(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewStartOptions()
+	o.IOStreams = streams
+
+	c := &fakeClusterStopper{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", c.lastStart)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind started\n", out.String())
+}
+
+func TestStopClusterNotFound(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewStopOptions()
+	o.IOStreams = streams
+
+	c := &fakeClusterStopper{}
+	err := o.run(c, "dunkees")
+	if assert.Error(t, err) {
+		assert.True(t, errors.IsNotFound(err))
+	}
+	assert.Equal(t, "", c.lastStop)
+}
+
+type fakeClusterStopper struct {
+	clusters  map[string]*api.Cluster
+	lastStop  string
+	lastStart string
+}
+
+func (c *fakeClusterStopper) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, ok := c.clusters[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
+	}
+	return cluster, nil
+}
+
+func (c *fakeClusterStopper) Stop(ctx context.Context, name string) error {
+	c.lastStop = name
+	return nil
+}
+
+func (c *fakeClusterStopper) Start(ctx context.Context, name string) (*api.Cluster, error) {
+	c.lastStart = name
+	return c.clusters[name], nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+type VerifyOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewVerifyOptions() *VerifyOptions {
+	return &VerifyOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+}
+
+func (o *VerifyOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "verify cluster NAME",
+		Short: "Check that a cluster's nodes can pull images from its registry",
+		Long: "Check that a cluster's nodes can pull images from its registry.\n\n" +
+			"Pushes a tiny image to the registry from this machine, then pulls it on each node " +
+			"with crictl, using both the localhost:port and name:port aliases of the registry.",
+		Example: "  ctlptl verify cluster kind-kind",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	return cmd
+}
+
+func (o *VerifyOptions) Run(cmd *cobra.Command, args []string) {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		_, _ = fmt.Fprintf(o.ErrOut, "Unsupported type: %s\n", t)
+		os.Exit(1)
+	}
+
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args[1])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterVerifier interface {
+	clusterGetter
+	Verify(ctx context.Context, name string) (*cluster.Verification, error)
+}
+
+func (o *VerifyOptions) run(controller clusterVerifier, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.verify", nil)
+	defer a.Flush(time.Second)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl verify cluster kind' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+	return verifyCluster(ctx, controller, c.Name, o.Out)
+}
+
+// Verifies the registry connection of a cluster, and prints per-node results.
+//
+// Returns an error if any node couldn't pull.
+func verifyCluster(ctx context.Context, controller clusterVerifier, name string, out io.Writer) error {
+	v, err := controller.Verify(ctx, name)
+	if err != nil {
+		return fmt.Errorf("verifying cluster %s: %v", name, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Pushed %s to registry %s\n", v.Image, v.Registry)
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "NODE\tIMAGE\tRESULT\n")
+	failed := 0
+	for _, r := range v.Results {
+		result := "ok"
+		if r.Error != "" {
+			failed++
+			result = "failed: " + r.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Node, r.Image, result)
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("cluster %s: %d of %d pulls from registry %s failed", name, failed, len(v.Results), v.Registry)
+	}
+	return nil
+}
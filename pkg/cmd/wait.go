@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+	"github.com/tilt-dev/ctlptl/pkg/config"
+)
+
+func NewWaitCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for a cluster to reach a condition",
+	}
+
+	cmd.AddCommand(NewWaitClusterOptions().Command())
+
+	return cmd
+}
+
+type WaitClusterOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+
+	For     string
+	Timeout time.Duration
+}
+
+func NewWaitClusterOptions() *WaitClusterOptions {
+	return &WaitClusterOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("condition met"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+		For:        "ready",
+	}
+}
+
+func (o *WaitClusterOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "cluster NAME",
+		Short: "Wait for a cluster to pass its readiness gates",
+		Long: "Wait for a cluster to pass its readiness gates.\n\n" +
+			"A cluster is ready when its apiserver responds, its nodes are Ready, its DNS is available, " +
+			"and the default ServiceAccount exists. Gates can be turned off under 'wait:' in the cluster config.",
+		Example: "  ctlptl wait cluster kind-kind --for=ready --timeout=10m",
+		Run:     o.Run,
+		Args:    cobra.ExactArgs(1),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.For, "for", o.For, "The condition to wait for. Currently only 'ready' is supported.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout,
+		"How long to wait for the cluster. Overrides timeouts.create in ~/.ctlptl/config.yaml. Defaults to 5m.")
+
+	return cmd
+}
+
+func (o *WaitClusterOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+	controller.SetTimeouts(config.Timeouts{Create: o.Timeout})
+
+	err = o.run(controller, args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterWaiter interface {
+	clusterGetter
+	WaitReady(ctx context.Context, name string) (*api.Cluster, error)
+}
+
+func (o *WaitClusterOptions) run(controller clusterWaiter, name string) error {
+	a, err := newAnalytics()
+	if err != nil {
+		return err
+	}
+	a.Incr("cmd.wait.cluster", nil)
+	defer a.Flush(time.Second)
+
+	err = validateTimeoutFlags(o.Timeout, 0)
+	if err != nil {
+		return err
+	}
+
+	if o.For != "ready" {
+		return fmt.Errorf("unsupported condition --for=%s: must be 'ready'", o.For)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	// Normalize the name of the cluster so that
+	// 'ctlptl wait cluster kind' works.
+	c, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	c, err = controller.WaitReady(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(c, o.Out)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/cluster"
+)
+
+func TestWaitCluster(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewWaitClusterOptions()
+	o.IOStreams = streams
+
+	c := &fakeClusterWaiter{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err := o.run(c, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", c.lastWait)
+	assert.Equal(t, "cluster.ctlptl.dev/kind-kind condition met\n", out.String())
+}
+
+func TestWaitClusterNegativeTimeout(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewWaitClusterOptions()
+	o.IOStreams = streams
+	err := o.Command().Flags().Set("timeout", "-1m")
+	require.NoError(t, err)
+
+	c := &fakeClusterWaiter{clusters: map[string]*api.Cluster{
+		"kind-kind": {TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+	}}
+	err = o.run(c, "kind")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid --timeout -1m0s: must be a positive duration, like 5m", err.Error())
+	}
+	assert.Equal(t, "", c.lastWait)
+}
+
+func TestWaitClusterUnsupportedCondition(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewWaitClusterOptions()
+	o.IOStreams = streams
+	o.For = "delete"
+
+	c := &fakeClusterWaiter{}
+	err := o.run(c, "kind")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported condition --for=delete")
+	}
+	assert.Equal(t, "", c.lastWait)
+}
+
+type fakeClusterWaiter struct {
+	clusters map[string]*api.Cluster
+	lastWait string
+}
+
+func (c *fakeClusterWaiter) Get(ctx context.Context, name string) (*api.Cluster, error) {
+	cluster, ok := c.clusters[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "ctlptl.dev", Resource: "clusters"}, name)
+	}
+	return cluster, nil
+}
+
+func (c *fakeClusterWaiter) WaitReady(ctx context.Context, name string) (*api.Cluster, error) {
+	c.lastWait = name
+	return c.clusters[name], nil
+}
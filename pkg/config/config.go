@@ -0,0 +1,132 @@
+// Global ctlptl settings that apply to every cluster, read from
+// ~/.ctlptl/config.yaml.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+)
+
+// Points ctlptl at a config file other than ~/.ctlptl/config.yaml.
+const pathEnv = "CTLPTL_CONFIG"
+
+type Config struct {
+	Timeouts Timeouts `yaml:"timeouts,omitempty"`
+}
+
+// How long ctlptl waits on slow operations.
+//
+// Zero values mean "use the default", so that timeouts
+// from flags can be layered on top of timeouts from the config file.
+type Timeouts struct {
+	// How long to wait for a new cluster to become healthy.
+	Create time.Duration `yaml:"create,omitempty"`
+
+	// How long to wait for a new cluster to appear in the kubeconfig.
+	KubeConfig time.Duration `yaml:"kubeconfig,omitempty"`
+
+	// How long to wait for a cluster's apiserver to respond before
+	// we mark the cluster as broken.
+	HealthCheck time.Duration `yaml:"healthCheck,omitempty"`
+
+	// How long to wait for the deployments in a cluster's manifests
+	// to become available.
+	Deployments time.Duration `yaml:"deployments,omitempty"`
+
+	// How long to wait for Docker Desktop to boot.
+	DockerDesktopStart time.Duration `yaml:"dockerDesktopStart,omitempty"`
+
+	// How long to wait for Docker Desktop to restart after changing its settings.
+	DockerDesktopRestart time.Duration `yaml:"dockerDesktopRestart,omitempty"`
+}
+
+// Returns the timeouts in t, overridden by the ones set in o.
+func (t Timeouts) Merge(o Timeouts) Timeouts {
+	override := func(dst *time.Duration, src time.Duration) {
+		if src != 0 {
+			*dst = src
+		}
+	}
+	override(&t.Create, o.Create)
+	override(&t.KubeConfig, o.KubeConfig)
+	override(&t.HealthCheck, o.HealthCheck)
+	override(&t.Deployments, o.Deployments)
+	override(&t.DockerDesktopStart, o.DockerDesktopStart)
+	override(&t.DockerDesktopRestart, o.DockerDesktopRestart)
+	return t
+}
+
+func (t Timeouts) validate() error {
+	for _, f := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"create", t.Create},
+		{"kubeconfig", t.KubeConfig},
+		{"healthCheck", t.HealthCheck},
+		{"deployments", t.Deployments},
+		{"dockerDesktopStart", t.DockerDesktopStart},
+		{"dockerDesktopRestart", t.DockerDesktopRestart},
+	} {
+		if f.d < 0 {
+			return fmt.Errorf("invalid timeouts.%s %s: must be a positive duration, like 5m", f.name, f.d)
+		}
+	}
+	return nil
+}
+
+// The path of the config file.
+func Path() (string, error) {
+	if p := os.Getenv(pathEnv); p != "" {
+		return p, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ctlptl", "config.yaml"), nil
+}
+
+// Reads the config file. If there's no config file, returns an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("reading ctlptl config: %v", err)
+	}
+
+	config, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf("reading ctlptl config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+func Parse(contents []byte) (*Config, error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err := decoder.Decode(config)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	err = config.Timeouts.validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(`
+timeouts:
+  create: 10m
+  healthCheck: 10s
+  dockerDesktopStart: 90s
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Timeouts{
+		Create:             10 * time.Minute,
+		HealthCheck:        10 * time.Second,
+		DockerDesktopStart: 90 * time.Second,
+	}, config.Timeouts)
+}
+
+func TestParseEmpty(t *testing.T) {
+	config, err := Parse([]byte(""))
+	require.NoError(t, err)
+	assert.Equal(t, Config{}, *config)
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse([]byte("timeouts:\n  creat: 10m\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field creat not found")
+	}
+}
+
+func TestParseNegativeTimeout(t *testing.T) {
+	_, err := Parse([]byte("timeouts:\n  kubeconfig: -1m\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid timeouts.kubeconfig -1m0s")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(pathEnv, path)
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, Config{}, *config)
+
+	require.NoError(t, os.WriteFile(path, []byte("timeouts:\n  create: 15m\n"), 0644))
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, config.Timeouts.Create)
+
+	require.NoError(t, os.WriteFile(path, []byte("timeouts: [\n"), 0644))
+	_, err = Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reading ctlptl config "+path)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Timeouts{Create: 5 * time.Minute, HealthCheck: 3 * time.Second}
+	merged := base.Merge(Timeouts{HealthCheck: 10 * time.Second, Deployments: time.Minute})
+	assert.Equal(t, Timeouts{
+		Create:      5 * time.Minute,
+		HealthCheck: 10 * time.Second,
+		Deployments: time.Minute,
+	}, merged)
+}
@@ -6,6 +6,11 @@ import (
 
 const ContainerLabelRole = "dev.tilt.ctlptl.role"
 
+// Labels that record how ctlptl configured a registry container,
+// so that we can read the config back.
+const ContainerLabelRegistryFlavor = "dev.tilt.ctlptl.registry.flavor"
+const ContainerLabelRegistryContainerPort = "dev.tilt.ctlptl.registry.containerPort"
+
 // Checks whether the Docker daemon is running on a local machine.
 // Remote docker daemons will likely need a port forwarder to work properly.
 func IsLocalHost(dockerHost string) bool {
@@ -20,9 +25,28 @@ func IsLocalHost(dockerHost string) bool {
 		strings.HasPrefix(dockerHost, "npipe:") ||
 
 		// https://github.com/moby/moby/blob/master/client/client_unix.go#L6
+		// Also covers Podman's Docker-compatible socket, including
+		// the socket that `podman machine` forwards to the host.
 		strings.HasPrefix(dockerHost, "unix:")
 }
 
+// Checks whether the DOCKER_HOST looks like Podman's Docker-compatible API socket.
+//
+// Podman doesn't have Docker's default "bridge" network, and its containers
+// aren't visible to the docker CLI, so callers may need to special-case it.
+//
+// https://docs.podman.io/en/latest/markdown/podman-system-service.1.html
+func IsPodmanHost(dockerHost string) bool {
+	if !strings.HasPrefix(dockerHost, "unix:") {
+		return false
+	}
+	// Rootless: unix:///run/user/1000/podman/podman.sock
+	// Rootful: unix:///run/podman/podman.sock
+	// Podman machine: unix:///var/folders/.../podman/podman-machine-default-api.sock
+	return strings.Contains(dockerHost, "/podman/") ||
+		strings.HasSuffix(dockerHost, "/podman.sock")
+}
+
 // Checks whether the DOCKER_HOST looks like a local Docker Engine.
 func IsLocalDockerEngineHost(dockerHost string) bool {
 	if strings.HasPrefix(dockerHost, "unix:") {
@@ -25,6 +25,8 @@ func TestIsLocalDockerHost(t *testing.T) {
 		dockerHostTestCase{"unix:///Users/USER/.colima/docker.sock", true, false},
 		dockerHostTestCase{"unix:///Users/USER/.docker/desktop/docker.sock", true, true},
 		dockerHostTestCase{"unix:///Users/USER/.docker/run/docker.sock", true, true},
+		dockerHostTestCase{"unix:///run/user/1000/podman/podman.sock", true, false},
+		dockerHostTestCase{"unix:///run/podman/podman.sock", true, false},
 	}
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("%s-%d", t.Name(), i), func(t *testing.T) {
@@ -59,3 +61,13 @@ func TestIsLocalDockerDesktop(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPodmanHost(t *testing.T) {
+	assert.True(t, IsPodmanHost("unix:///run/user/1000/podman/podman.sock"))
+	assert.True(t, IsPodmanHost("unix:///run/podman/podman.sock"))
+	assert.True(t, IsPodmanHost("unix:///var/folders/xy/T/podman/podman-machine-default-api.sock"))
+	assert.False(t, IsPodmanHost(""))
+	assert.False(t, IsPodmanHost("unix:///var/run/docker.sock"))
+	assert.False(t, IsPodmanHost("unix:///Users/USER/.colima/docker.sock"))
+	assert.False(t, IsPodmanHost("tcp://localhost:2375"))
+}
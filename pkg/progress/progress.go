@@ -0,0 +1,164 @@
+// Reports the phases that ctlptl goes through while it sets up clusters
+// and registries, so that CI can time each phase and see which one failed.
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Phase string
+
+const (
+	PhaseMachineEnsure   Phase = "machine-ensure"
+	PhaseDelete          Phase = "delete"
+	PhaseRestart         Phase = "restart"
+	PhaseRegistryApply   Phase = "registry-apply"
+	PhaseRegistryDelete  Phase = "registry-delete"
+	PhaseCreate          Phase = "create"
+	PhaseWaitContext     Phase = "wait-context"
+	PhaseWaitHealth      Phase = "wait-health"
+	PhaseWriteSpec       Phase = "write-spec"
+	PhaseRegistryHosting Phase = "registry-hosting"
+	PhaseLoadImages      Phase = "load-images"
+	PhaseApplyManifests  Phase = "apply-manifests"
+	PhaseRollback        Phase = "rollback"
+)
+
+type EventType string
+
+const (
+	EventPhaseStart EventType = "phase-start"
+	EventPhaseEnd   EventType = "phase-end"
+
+	// Free-form progress text, like "Waiting 5m for...".
+	EventMessage EventType = "message"
+)
+
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+
+	Phase Phase `json:"phase,omitempty"`
+
+	// The cluster or registry that the phase works on.
+	Name string `json:"name,omitempty"`
+
+	// How long the phase took. Only set on phase-end events.
+	DurationMs *int64 `json:"durationMs,omitempty"`
+
+	// Why the phase failed. Only set on phase-end events.
+	Error string `json:"error,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+type Reporter interface {
+	// Marks the start of a phase. Call the returned func with the
+	// phase's error (or nil) when the phase ends.
+	Start(phase Phase, name string) func(err error)
+}
+
+// The default reporter, which keeps phases silent.
+// Progress text goes straight to the output, as it always has.
+var Text Reporter = textReporter{}
+
+type textReporter struct{}
+
+func (textReporter) Start(phase Phase, name string) func(err error) {
+	return func(err error) {}
+}
+
+// Reports phases and progress text as newline-delimited JSON events.
+//
+// Also an io.Writer, so that it can stand in for the progress output.
+// Each line written to it becomes a message event.
+type JSONReporter struct {
+	mu  sync.Mutex
+	out io.Writer
+	buf bytes.Buffer
+	now func() time.Time
+
+	// The first error writing to out.
+	err error
+}
+
+var _ Reporter = &JSONReporter{}
+var _ io.Writer = &JSONReporter{}
+
+func NewJSONReporter(out io.Writer) *JSONReporter {
+	return &JSONReporter{out: out, now: time.Now}
+}
+
+func (r *JSONReporter) Start(phase Phase, name string) func(err error) {
+	r.mu.Lock()
+	start := r.now()
+	r.emit(Event{Time: start, Type: EventPhaseStart, Phase: phase, Name: name})
+	r.mu.Unlock()
+
+	return func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		end := r.now()
+		ms := end.Sub(start).Milliseconds()
+		e := Event{Time: end, Type: EventPhaseEnd, Phase: phase, Name: name, DurationMs: &ms}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		r.emit(e)
+	}
+}
+
+func (r *JSONReporter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf.Write(p)
+	for {
+		line, err := r.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line until the rest of it arrives.
+			r.buf.Reset()
+			r.buf.WriteString(line)
+			break
+		}
+		r.message(line)
+	}
+	return len(p), nil
+}
+
+// Emits any progress text that didn't end in a newline.
+func (r *JSONReporter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.message(r.buf.String())
+	r.buf.Reset()
+	if r.err != nil {
+		return fmt.Errorf("writing progress: %v", r.err)
+	}
+	return nil
+}
+
+func (r *JSONReporter) message(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	r.emit(Event{Time: r.now(), Type: EventMessage, Message: line})
+}
+
+func (r *JSONReporter) emit(e Event) {
+	data, err := json.Marshal(e)
+	if err == nil {
+		_, err = r.out.Write(append(data, '\n'))
+	}
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+}
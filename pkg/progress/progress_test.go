@@ -0,0 +1,54 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReporter(out *bytes.Buffer) *JSONReporter {
+	r := NewJSONReporter(out)
+	t := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.now = func() time.Time {
+		t = t.Add(1500 * time.Millisecond)
+		return t
+	}
+	return r
+}
+
+func TestJSONReporterPhases(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	r := newTestReporter(out)
+
+	done := r.Start(PhaseCreate, "kind-kind")
+	done(nil)
+	done = r.Start(PhaseWaitHealth, "kind-kind")
+	done(fmt.Errorf("timed out"))
+	require.NoError(t, r.Flush())
+
+	assert.Equal(t,
+		`{"time":"2024-01-02T03:04:06.5Z","type":"phase-start","phase":"create","name":"kind-kind"}
+{"time":"2024-01-02T03:04:08Z","type":"phase-end","phase":"create","name":"kind-kind","durationMs":1500}
+{"time":"2024-01-02T03:04:09.5Z","type":"phase-start","phase":"wait-health","name":"kind-kind"}
+{"time":"2024-01-02T03:04:11Z","type":"phase-end","phase":"wait-health","name":"kind-kind","durationMs":1500,"error":"timed out"}
+`, out.String())
+}
+
+func TestJSONReporterMessages(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	r := newTestReporter(out)
+
+	_, _ = fmt.Fprint(r, "Waiting 5m for ")
+	assert.Equal(t, "", out.String())
+	_, _ = fmt.Fprint(r, "cluster\n\n   \nDone")
+	assert.Equal(t,
+		`{"time":"2024-01-02T03:04:06.5Z","type":"message","message":"Waiting 5m for cluster"}
+`, out.String())
+
+	require.NoError(t, r.Flush())
+	assert.Contains(t, out.String(), `"message":"Done"`)
+}
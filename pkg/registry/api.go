@@ -0,0 +1,361 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+	"github.com/tilt-dev/ctlptl/pkg/docker"
+)
+
+// Manifest types we know how to read, in order of preference.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// A minimal client for the registry HTTP API.
+//
+// https://distribution.github.io/distribution/spec/api/
+//
+// Only supports the unauthenticated, plain-HTTP registries
+// that ctlptl runs locally.
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newAPIClient(baseURL string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// The URL of the registry API, as seen from the host machine.
+func hostURL(r *api.Registry, dockerHost string) (string, error) {
+	if r.Status.HostPort == 0 {
+		return "", fmt.Errorf("registry %s is not listening on a host port", r.Name)
+	}
+
+	host := r.Status.ListenAddress
+	if host == "::" && docker.IsLocalHost(dockerHost) {
+		// Published on IPv6 only.
+		host = "::1"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" || !docker.IsLocalHost(dockerHost) {
+		// On remote Docker, the registry is forwarded to localhost.
+		host = "localhost"
+	}
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(r.Status.HostPort))), nil
+}
+
+// Checks that the registry API is answering requests.
+//
+// https://distribution.github.io/distribution/spec/api/#api-version-check
+func (c *apiClient) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// A registry that requires auth is still up.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("GET /v2/: %s", resp.Status)
+	}
+	return nil
+}
+
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
+// Lists all the repositories in the registry.
+func (c *apiClient) catalog(ctx context.Context) ([]string, error) {
+	result := []string{}
+	next := "/v2/_catalog?n=1000"
+	for next != "" {
+		resp := catalogResponse{}
+		header, err := c.getJSON(ctx, next, nil, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("listing repositories: %v", err)
+		}
+		result = append(result, resp.Repositories...)
+		next = nextLink(header)
+	}
+	return result, nil
+}
+
+type tagsResponse struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// Lists all the tags in a repository.
+func (c *apiClient) tags(ctx context.Context, repo string) ([]string, error) {
+	result := []string{}
+	next := fmt.Sprintf("/v2/%s/tags/list?n=1000", repo)
+	for next != "" {
+		resp := tagsResponse{}
+		header, err := c.getJSON(ctx, next, nil, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("listing tags of %s: %v", repo, err)
+		}
+		result = append(result, resp.Tags...)
+		next = nextLink(header)
+	}
+	return result, nil
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// A blob and its descriptor.
+type blob struct {
+	desc descriptor
+	data []byte
+}
+
+func newBlob(mediaType string, data []byte) blob {
+	return blob{
+		desc: descriptor{
+			MediaType: mediaType,
+			Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256(data)),
+			Size:      int64(len(data)),
+		},
+		data: data,
+	}
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion,omitempty"`
+	MediaType     string       `json:"mediaType"`
+	Config        *descriptor  `json:"config,omitempty"`
+	Layers        []descriptor `json:"layers,omitempty"`
+	Manifests     []descriptor `json:"manifests,omitempty"`
+}
+
+type imageConfig struct {
+	Created time.Time `json:"created"`
+}
+
+// Information about the image behind a tag.
+type tagInfo struct {
+	Digest string
+
+	// The total size of the image config and layers.
+	// For multi-platform images, the size of the first platform.
+	Size int64
+
+	// When the image was built, according to the image config.
+	// Zero if unknown.
+	Created time.Time
+}
+
+// Reads the digest, size, and creation time of the image behind a tag.
+func (c *apiClient) tagInfo(ctx context.Context, repo, tag string) (tagInfo, error) {
+	m := manifest{}
+	header, err := c.getJSON(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repo, tag),
+		map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")}, &m)
+	if err != nil {
+		return tagInfo{}, fmt.Errorf("reading %s:%s: %v", repo, tag, err)
+	}
+
+	info := tagInfo{Digest: header.Get("Docker-Content-Digest")}
+
+	// For multi-platform images, pick the first image
+	// to estimate the size and creation time.
+	if len(m.Manifests) > 0 {
+		first := m.Manifests[0].Digest
+		m = manifest{}
+		_, err = c.getJSON(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repo, first),
+			map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")}, &m)
+		if err != nil {
+			return tagInfo{}, fmt.Errorf("reading %s:%s: %v", repo, tag, err)
+		}
+	}
+
+	if m.Config == nil {
+		return info, nil
+	}
+
+	info.Size = m.Config.Size
+	for _, l := range m.Layers {
+		info.Size += l.Size
+	}
+
+	config := imageConfig{}
+	_, err = c.getJSON(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repo, m.Config.Digest), nil, &config)
+	if err != nil {
+		return tagInfo{}, fmt.Errorf("reading %s:%s config: %v", repo, tag, err)
+	}
+	info.Created = config.Created
+	return info, nil
+}
+
+// Deletes a manifest, and all the tags pointing to it.
+//
+// The registry must have REGISTRY_STORAGE_DELETE_ENABLED=true.
+func (c *apiClient) deleteManifest(ctx context.Context, repo, digest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete,
+		c.baseURL+fmt.Sprintf("/v2/%s/manifests/%s", repo, digest), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("deleting %s@%s: %v", repo, digest, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("deleting %s@%s: %s: %s", repo, digest, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Uploads a blob in a single request, unless the registry already has it.
+//
+// https://distribution.github.io/distribution/spec/api/#monolithic-upload
+func (c *apiClient) uploadBlob(ctx context.Context, repo string, b blob) error {
+	_, err := c.send(ctx, http.MethodHead, c.baseURL+fmt.Sprintf("/v2/%s/blobs/%s", repo, b.desc.Digest), "", nil, http.StatusOK)
+	if err == nil {
+		return nil
+	}
+
+	header, err := c.send(ctx, http.MethodPost, c.baseURL+fmt.Sprintf("/v2/%s/blobs/uploads/", repo), "", nil, http.StatusAccepted)
+	if err != nil {
+		return fmt.Errorf("uploading blob to %s: %v", repo, err)
+	}
+
+	// The upload location may be relative to the registry.
+	base, err := url.Parse(c.baseURL + "/")
+	if err != nil {
+		return err
+	}
+	location, err := base.Parse(header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("uploading blob to %s: parsing upload location: %v", repo, err)
+	}
+	query := location.Query()
+	query.Set("digest", b.desc.Digest)
+	location.RawQuery = query.Encode()
+
+	_, err = c.send(ctx, http.MethodPut, location.String(), "application/octet-stream", b.data, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("uploading blob to %s: %v", repo, err)
+	}
+	return nil
+}
+
+// Tags a manifest.
+func (c *apiClient) putManifest(ctx context.Context, repo, tag string, m manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, http.MethodPut, c.baseURL+fmt.Sprintf("/v2/%s/manifests/%s", repo, tag), m.MediaType, data, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("pushing %s:%s: %v", repo, tag, err)
+	}
+	return nil
+}
+
+// Sends a request, and checks that the response has the expected status.
+func (c *apiClient) send(ctx context.Context, method, url, contentType string, body []byte, expected int) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != expected {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return resp.Header, nil
+}
+
+func (c *apiClient) getJSON(ctx context.Context, path string, headers map[string]string, v interface{}) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, val := range headers {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: decoding response: %v", path, err)
+	}
+	return resp.Header, nil
+}
+
+// Parses the pagination header.
+//
+// Link: </v2/_catalog?last=b&n=2>; rel="next"
+func nextLink(header http.Header) string {
+	link := header.Get("Link")
+	if link == "" {
+		return ""
+	}
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start == -1 || end <= start {
+		return ""
+	}
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
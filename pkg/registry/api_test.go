@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+func TestProbeHTTP(t *testing.T) {
+	s := newFakeRegistryServer(t)
+	assert.NoError(t, probeHTTP(context.Background(), s.server.URL))
+
+	s.server.Close()
+	assert.Error(t, probeHTTP(context.Background(), s.server.URL))
+}
+
+func TestNextLink(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, "", nextLink(header))
+
+	header.Set("Link", `</v2/_catalog?last=b&n=2>; rel="next"`)
+	assert.Equal(t, "/v2/_catalog?last=b&n=2", nextLink(header))
+}
+
+func TestHostURL(t *testing.T) {
+	local := "unix:///var/run/docker.sock"
+	remote := "tcp://10.0.0.5:2376"
+	cases := []struct {
+		listenAddress string
+		dockerHost    string
+		expected      string
+	}{
+		{"127.0.0.1", local, "http://127.0.0.1:5001"},
+		{"0.0.0.0", local, "http://localhost:5001"},
+		{"::1", local, "http://[::1]:5001"},
+		{"::", local, "http://[::1]:5001"},
+		{"::1", remote, "http://localhost:5001"},
+	}
+	for _, c := range cases {
+		t.Run(c.listenAddress+" "+c.dockerHost, func(t *testing.T) {
+			r := &api.Registry{Name: "kind-registry"}
+			r.Status.ListenAddress = c.listenAddress
+			r.Status.HostPort = 5001
+			actual, err := hostURL(r, c.dockerHost)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tilt-dev/ctlptl/pkg/docker"
+)
+
+// Registry implementations that ctlptl knows how to configure.
+const (
+	// The CNCF Distribution registry, a.k.a. registry:2.
+	FlavorDistribution = "distribution"
+
+	// https://zotregistry.dev/
+	FlavorZot = "zot"
+)
+
+const DefaultZotImageRef = "ghcr.io/project-zot/zot:latest"
+
+// The port that registry images listen on unless configured otherwise.
+const defaultContainerPort = 5000
+
+func validateFlavor(flavor string) error {
+	switch flavor {
+	case "", FlavorDistribution, FlavorZot:
+		return nil
+	}
+	return fmt.Errorf("unknown registry flavor %q (expected one of: %s, %s)", flavor, FlavorDistribution, FlavorZot)
+}
+
+func defaultImageForFlavor(flavor string) string {
+	if flavor == FlavorZot {
+		return DefaultZotImageRef
+	}
+	return DefaultRegistryImageRef
+}
+
+// Env vars that configure the registry, unless the user overrides them.
+func defaultEnvForFlavor(flavor string, containerPort int) map[string]string {
+	switch flavor {
+	case FlavorZot:
+		// Zot reads its config from a file, and enables deletes by default.
+		return nil
+	default:
+		env := map[string]string{
+			"REGISTRY_STORAGE_DELETE_ENABLED": "true",
+		}
+		if containerPort != defaultContainerPort {
+			env["REGISTRY_HTTP_ADDR"] = fmt.Sprintf(":%d", containerPort)
+		}
+		return env
+	}
+}
+
+// Reads the flavor that ctlptl recorded on the container.
+//
+// Registries created by older versions of ctlptl don't have the label,
+// so fall back to guessing from the image.
+func flavorFromContainer(labels map[string]string, image string) string {
+	flavor := labels[docker.ContainerLabelRegistryFlavor]
+	if flavor != "" {
+		return flavor
+	}
+	if strings.Contains(image, "zot") {
+		return FlavorZot
+	}
+	return FlavorDistribution
+}
+
+// Reads the container port that ctlptl recorded on the container.
+func containerPortFromContainer(labels map[string]string) int {
+	port, err := strconv.Atoi(labels[docker.ContainerLabelRegistryContainerPort])
+	if err != nil || port <= 0 {
+		return defaultContainerPort
+	}
+	return port
+}
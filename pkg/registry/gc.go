@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+)
+
+// The config file baked into the distribution registry image.
+const registryConfigPath = "/etc/docker/registry/config.yml"
+
+type GCOptions struct {
+	// Delete tags whose images were created longer ago than this.
+	// Zero means don't delete tags by age.
+	OlderThan time.Duration
+
+	// Keep only this many of the most recently created tags in each repository.
+	// Zero means don't delete tags by count.
+	KeepLast int
+}
+
+type GCResult struct {
+	// Tags deleted through the registry API, as repo:tag.
+	DeletedTags []string
+
+	// Disk usage of the registry storage before and after garbage collection.
+	BytesBefore int64
+	BytesAfter  int64
+
+	// True if the registry deletes unreferenced blobs on its own schedule,
+	// so the reclaimed space wasn't measured.
+	Background bool
+}
+
+func (r GCResult) BytesReclaimed() int64 {
+	if r.BytesAfter > r.BytesBefore {
+		return 0
+	}
+	return r.BytesBefore - r.BytesAfter
+}
+
+// Reclaim disk space in a registry.
+//
+// First deletes old tags through the registry API (if requested by the options),
+// then runs the registry's garbage collector inside the container to delete any
+// blobs that are no longer referenced.
+//
+// Zot runs its own garbage collector in the background, so for zot registries
+// we only delete tags.
+func (c *Controller) GC(ctx context.Context, name string, options GCOptions) (*GCResult, error) {
+	registry, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if registry.Status.State != containerStateRunning {
+		return nil, fmt.Errorf("registry %s is not running (state: %s)", name, registry.Status.State)
+	}
+
+	result := &GCResult{Background: registry.Status.Flavor == FlavorZot}
+	if !result.Background {
+		result.BytesBefore, err = c.diskUsage(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.OlderThan > 0 || options.KeepLast > 0 {
+		baseURL, err := hostURL(registry, c.dockerCLI.Client().DaemonHost())
+		if err != nil {
+			return nil, err
+		}
+		result.DeletedTags, err = c.deleteTags(ctx, newAPIClient(baseURL), options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Background {
+		return result, nil
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Running garbage collection in registry %s...\n", name)
+	out := bytes.NewBuffer(nil)
+	err = c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: out, ErrOut: out},
+		dctr.Binary(c.dockerCLI.Client()), "exec", name, "registry", "garbage-collect", "--delete-untagged", registryConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("garbage collecting registry %s: %v\n%s", name, err, out.String())
+	}
+
+	result.BytesAfter, err = c.diskUsage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Deletes the tags that the options don't want to keep.
+//
+// The registry API can only delete manifests, which deletes every tag
+// that points to that manifest. So we never delete a manifest
+// that's also referenced by a tag we're keeping.
+func (c *Controller) deleteTags(ctx context.Context, client *apiClient, options GCOptions) ([]string, error) {
+	repos, err := client.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Time{}
+	if options.OlderThan > 0 {
+		cutoff = time.Now().Add(-options.OlderThan)
+	}
+
+	deleted := []string{}
+	for _, repo := range repos {
+		tags, err := client.tags(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+
+		infos := make(map[string]tagInfo, len(tags))
+		for _, tag := range tags {
+			info, err := client.tagInfo(ctx, repo, tag)
+			if err != nil {
+				return nil, err
+			}
+			infos[tag] = info
+		}
+
+		// Newest first.
+		sort.SliceStable(tags, func(i, j int) bool {
+			return infos[tags[i]].Created.After(infos[tags[j]].Created)
+		})
+
+		keepDigests := map[string]bool{}
+		deleteDigests := map[string][]string{}
+		for i, tag := range tags {
+			info := infos[tag]
+			tooMany := options.KeepLast > 0 && i >= options.KeepLast
+			tooOld := !cutoff.IsZero() && !info.Created.IsZero() && info.Created.Before(cutoff)
+			if (tooMany || tooOld) && info.Digest != "" {
+				deleteDigests[info.Digest] = append(deleteDigests[info.Digest], tag)
+			} else {
+				keepDigests[info.Digest] = true
+			}
+		}
+
+		digests := make([]string, 0, len(deleteDigests))
+		for digest := range deleteDigests {
+			if !keepDigests[digest] {
+				digests = append(digests, digest)
+			}
+		}
+		sort.Strings(digests)
+
+		for _, digest := range digests {
+			err := client.deleteManifest(ctx, repo, digest)
+			if err != nil {
+				return nil, err
+			}
+			for _, tag := range deleteDigests[digest] {
+				_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleted %s:%s\n", repo, tag)
+				deleted = append(deleted, fmt.Sprintf("%s:%s", repo, tag))
+			}
+		}
+	}
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+// Measures the size of the registry storage, in bytes.
+func (c *Controller) diskUsage(ctx context.Context, name string) (int64, error) {
+	out := bytes.NewBuffer(nil)
+	err := c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: out, ErrOut: c.iostreams.ErrOut},
+		dctr.Binary(c.dockerCLI.Client()), "exec", name, "du", "-sk", registryStoragePath)
+	if err != nil {
+		return 0, fmt.Errorf("measuring registry %s storage: %v", name, err)
+	}
+
+	fields := strings.Fields(out.String())
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("measuring registry %s storage: unexpected output %q", name, out.String())
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("measuring registry %s storage: %v", name, err)
+	}
+	return kb * 1024, nil
+}
@@ -0,0 +1,327 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cexec "github.com/tilt-dev/ctlptl/internal/exec"
+)
+
+func TestGCNoTags(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+	f.setDiskUsage(2048, 1024)
+
+	result, err := f.c.GC(context.Background(), "kind-registry", GCOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string(nil), result.DeletedTags)
+	assert.Equal(t, int64(2048*1024), result.BytesBefore)
+	assert.Equal(t, int64(1024*1024), result.BytesAfter)
+	assert.Equal(t, int64(1024*1024), result.BytesReclaimed())
+
+	require.Len(t, f.execs, 3)
+	assert.Equal(t, []string{"docker", "exec", "kind-registry", "du", "-sk", "/var/lib/registry"}, f.execs[0])
+	assert.Equal(t, []string{"docker", "exec", "kind-registry",
+		"registry", "garbage-collect", "--delete-untagged", "/etc/docker/registry/config.yml"}, f.execs[1])
+}
+
+func TestGCKeepLast(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	s := newFakeRegistryServer(t)
+	s.push("app", "v1", "sha256:aaa", now.Add(-72*time.Hour))
+	s.push("app", "v2", "sha256:bbb", now.Add(-48*time.Hour))
+	s.push("app", "v3", "sha256:ccc", now.Add(-24*time.Hour))
+	s.push("app", "latest", "sha256:ccc", now.Add(-24*time.Hour))
+	s.push("other", "v1", "sha256:ddd", now.Add(-72*time.Hour))
+	f.useServer(s)
+	f.setDiskUsage(4096, 1024)
+
+	result, err := f.c.GC(context.Background(), "kind-registry", GCOptions{KeepLast: 2})
+	require.NoError(t, err)
+
+	// v3 and latest share a digest, so they count as the two newest tags.
+	assert.Equal(t, []string{"app:v1", "app:v2"}, result.DeletedTags)
+	assert.Equal(t, []string{"app@sha256:aaa", "app@sha256:bbb"}, s.deleted)
+	assert.Equal(t, int64(3072*1024), result.BytesReclaimed())
+}
+
+func TestGCOlderThan(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	s := newFakeRegistryServer(t)
+	s.push("app", "v1", "sha256:aaa", now.Add(-72*time.Hour))
+	s.push("app", "v2", "sha256:bbb", now.Add(-1*time.Hour))
+	s.push("other", "v1", "sha256:ddd", now.Add(-72*time.Hour))
+	f.useServer(s)
+	f.setDiskUsage(4096, 1024)
+
+	result, err := f.c.GC(context.Background(), "kind-registry", GCOptions{OlderThan: 48 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:v1", "other:v1"}, result.DeletedTags)
+	assert.Equal(t, []string{"app@sha256:aaa", "other@sha256:ddd"}, s.deleted)
+}
+
+func TestGCKeepsSharedDigest(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	s := newFakeRegistryServer(t)
+	s.push("app", "old", "sha256:aaa", now.Add(-72*time.Hour))
+	s.push("app", "new", "sha256:bbb", now.Add(-1*time.Hour))
+	// Re-tagged recently, but the image itself is old.
+	s.push("app", "stable", "sha256:aaa", now.Add(-72*time.Hour))
+	f.useServer(s)
+	f.setDiskUsage(1024, 1024)
+
+	result, err := f.c.GC(context.Background(), "kind-registry", GCOptions{KeepLast: 2})
+	require.NoError(t, err)
+
+	// Deleting "old" would also delete "stable", which we keep.
+	assert.Equal(t, []string{}, result.DeletedTags)
+	assert.Equal(t, []string(nil), s.deleted)
+}
+
+func TestGCZot(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	now := time.Now()
+	s := newFakeRegistryServer(t)
+	s.push("app", "v1", "sha256:aaa", now.Add(-72*time.Hour))
+	s.push("app", "v2", "sha256:bbb", now.Add(-24*time.Hour))
+	f.useServer(s)
+	f.docker.containers[0].Image = DefaultZotImageRef
+	f.setDiskUsage(4096, 1024)
+
+	result, err := f.c.GC(context.Background(), "kind-registry", GCOptions{KeepLast: 1})
+	require.NoError(t, err)
+
+	// Zot collects blobs on its own, and has no shell to exec into.
+	assert.True(t, result.Background)
+	assert.Equal(t, []string{"app:v1"}, result.DeletedTags)
+	assert.Len(t, f.execs, 0)
+}
+
+func TestGCNotRunning(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	r := kindRegistry()
+	r.State = "exited"
+	f.docker.containers = []container.Summary{r}
+
+	_, err := f.c.GC(context.Background(), "kind-registry", GCOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not running")
+	assert.Len(t, f.execs, 0)
+}
+
+// Responds to du with the given sizes (in kilobytes) before and after gc.
+func (f *fixture) setDiskUsage(before, after int64) {
+	gcDone := false
+	f.c.runner = cexec.NewFakeCmdRunner(func(argv []string) string {
+		f.execs = append(f.execs, argv)
+		if len(argv) > 4 && argv[3] == "registry" {
+			gcDone = true
+			return ""
+		}
+		if len(argv) > 4 && argv[3] == "du" {
+			if gcDone {
+				return fmt.Sprintf("%d\t/var/lib/registry\n", after)
+			}
+			return fmt.Sprintf("%d\t/var/lib/registry\n", before)
+		}
+		return ""
+	})
+}
+
+// Points the fixture's registry container at the fake registry server.
+func (f *fixture) useServer(s *fakeRegistryServer) {
+	_, portStr, err := net.SplitHostPort(s.server.Listener.Addr().String())
+	require.NoError(f.t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(f.t, err)
+
+	r := kindRegistry()
+	r.Ports[0].IP = netip.MustParseAddr("127.0.0.1")
+	r.Ports[0].PublicPort = uint16(port)
+	f.docker.containers = []container.Summary{r}
+}
+
+type fakeImage struct {
+	digest  string
+	created time.Time
+}
+
+// A stand-in for the registry HTTP API.
+type fakeRegistryServer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	repos   []string
+	tags    map[string][]string
+	images  map[string]fakeImage
+	deleted []string
+
+	// Pushed blobs by digest, and pushed manifests by repo:tag.
+	blobs     map[string][]byte
+	manifests map[string][]byte
+}
+
+func newFakeRegistryServer(t *testing.T) *fakeRegistryServer {
+	s := &fakeRegistryServer{
+		t:         t,
+		tags:      map[string][]string{},
+		images:    map[string]fakeImage{},
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *fakeRegistryServer) push(repo, tag, digest string, created time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tags[repo]; !ok {
+		s.repos = append(s.repos, repo)
+	}
+	s.tags[repo] = append(s.tags[repo], tag)
+	s.images[repo+":"+tag] = fakeImage{digest: digest, created: created}
+}
+
+func (s *fakeRegistryServer) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" {
+		s.writeJSON(w, struct{}{})
+		return
+	}
+	if path == "_catalog" {
+		s.writeJSON(w, catalogResponse{Repositories: s.repos})
+		return
+	}
+
+	if i := strings.Index(path, "/blobs/uploads/"); i != -1 {
+		s.upload(w, req, path[:i])
+		return
+	}
+
+	for _, sep := range []string{"/tags/list", "/manifests/", "/blobs/"} {
+		i := strings.Index(path, sep)
+		if i == -1 {
+			continue
+		}
+		repo, ref := path[:i], path[i+len(sep):]
+		switch sep {
+		case "/tags/list":
+			s.writeJSON(w, tagsResponse{Name: repo, Tags: s.tags[repo]})
+		case "/manifests/":
+			if req.Method == http.MethodDelete {
+				s.deleteManifest(w, repo, ref)
+				return
+			}
+			if req.Method == http.MethodPut {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(s.t, err)
+				s.manifests[repo+":"+ref] = body
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			img, ok := s.images[repo+":"+ref]
+			if !ok {
+				http.NotFound(w, req)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", img.digest)
+			s.writeJSON(w, manifest{
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Config:    &descriptor{Digest: "config-" + repo + "-" + ref, Size: 100},
+				Layers:    []descriptor{{Digest: "layer", Size: 1000}},
+			})
+		case "/blobs/":
+			if req.Method == http.MethodHead {
+				if _, ok := s.blobs[ref]; !ok {
+					http.NotFound(w, req)
+				}
+				return
+			}
+			tag := strings.TrimPrefix(ref, "config-"+repo+"-")
+			img, ok := s.images[repo+":"+tag]
+			if !ok {
+				http.NotFound(w, req)
+				return
+			}
+			s.writeJSON(w, imageConfig{Created: img.created})
+		}
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// Accepts monolithic blob uploads.
+func (s *fakeRegistryServer) upload(w http.ResponseWriter, req *http.Request, repo string) {
+	if req.Method == http.MethodPost {
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/upload-1?state=abc", repo))
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(s.t, err)
+	digest := req.URL.Query().Get("digest")
+	if digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) || req.URL.Query().Get("state") != "abc" {
+		http.Error(w, "digest invalid", http.StatusBadRequest)
+		return
+	}
+	s.blobs[digest] = body
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *fakeRegistryServer) deleteManifest(w http.ResponseWriter, repo, digest string) {
+	s.deleted = append(s.deleted, repo+"@"+digest)
+	remaining := []string{}
+	for _, tag := range s.tags[repo] {
+		if s.images[repo+":"+tag].digest == digest {
+			delete(s.images, repo+":"+tag)
+			continue
+		}
+		remaining = append(remaining, tag)
+	}
+	s.tags[repo] = remaining
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *fakeRegistryServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	require.NoError(s.t, err)
+}
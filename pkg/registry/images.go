@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/ctlptl/internal/dctr"
+	"github.com/tilt-dev/ctlptl/pkg/api"
+)
+
+var (
+	imageTypeMeta     = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "RegistryImage"}
+	imageListTypeMeta = api.TypeMeta{APIVersion: "ctlptl.dev/v1alpha1", Kind: "RegistryImageList"}
+)
+
+// Where the registry image keeps repository metadata.
+const registryRepositoriesPath = registryStoragePath + "/docker/registry/v2/repositories"
+
+// List the tagged images stored in a registry.
+func (c *Controller) ListImages(ctx context.Context, name string) (*api.RegistryImageList, error) {
+	registry, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if registry.Status.State != containerStateRunning {
+		return nil, fmt.Errorf("registry %s is not running (state: %s)", name, registry.Status.State)
+	}
+
+	baseURL, err := hostURL(registry, c.dockerCLI.Client().DaemonHost())
+	if err != nil {
+		return nil, err
+	}
+	client := newAPIClient(baseURL)
+
+	repos, err := client.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The registry API doesn't say when a tag was pushed,
+	// so read it off the tag's link file. Not all registry
+	// images store files this way, so this is best-effort.
+	//
+	// Zot stores images in OCI layout, which doesn't keep per-tag files.
+	pushTimes := map[string]time.Time{}
+	if registry.Status.Flavor != FlavorZot {
+		pushTimes, err = c.tagPushTimes(ctx, name)
+		if err != nil {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Warning: reading push times of registry %s: %v\n", name, err)
+		}
+	}
+
+	result := []api.RegistryImage{}
+	for _, repo := range repos {
+		tags, err := client.tags(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(tags)
+
+		for _, tag := range tags {
+			info, err := client.tagInfo(ctx, repo, tag)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, api.RegistryImage{
+				TypeMeta:          imageTypeMeta,
+				Registry:          name,
+				Repository:        repo,
+				Tag:               tag,
+				Digest:            info.Digest,
+				Size:              info.Size,
+				CreationTimestamp: metav1.Time{Time: info.Created},
+				PushTimestamp:     metav1.Time{Time: pushTimes[repo+":"+tag]},
+			})
+		}
+	}
+
+	return &api.RegistryImageList{
+		TypeMeta: imageListTypeMeta,
+		Items:    result,
+	}, nil
+}
+
+// Reads the modification time of each tag's link file,
+// which the registry rewrites on every push.
+//
+// Returns a map from repo:tag to push time.
+func (c *Controller) tagPushTimes(ctx context.Context, name string) (map[string]time.Time, error) {
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	err := c.runner.RunIO(ctx,
+		genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+		dctr.Binary(c.dockerCLI.Client()), "exec", name, "find", registryRepositoriesPath,
+		"-path", "*/_manifests/tags/*/current/link",
+		"-exec", "stat", "-c", "%Y %n", "{}", "+")
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, errOut.String())
+	}
+
+	result := map[string]time.Time{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		mtime, file, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(mtime, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// e.g., /var/lib/registry/docker/registry/v2/repositories/my/app/_manifests/tags/v1/current/link
+		rel := strings.TrimPrefix(file, registryRepositoriesPath+"/")
+		repo, tagPath, ok := strings.Cut(rel, "/_manifests/tags/")
+		if !ok {
+			continue
+		}
+		tag := path.Dir(path.Dir(tagPath))
+		result[repo+":"+tag] = time.Unix(sec, 0)
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cexec "github.com/tilt-dev/ctlptl/internal/exec"
+)
+
+func TestListImages(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	created := time.Unix(1600000000, 0).UTC()
+	s := newFakeRegistryServer(t)
+	s.push("my/app", "v1", "sha256:aaa", created)
+	s.push("my/app", "latest", "sha256:aaa", created)
+	s.push("other", "v2", "sha256:bbb", created)
+	f.useServer(s)
+
+	f.c.runner = cexec.NewFakeCmdRunner(func(argv []string) string {
+		f.execs = append(f.execs, argv)
+		return fmt.Sprintf("1700000000 %s/my/app/_manifests/tags/v1/current/link\n", registryRepositoriesPath) +
+			fmt.Sprintf("1700000100 %s/my/app/_manifests/tags/latest/current/link\n", registryRepositoriesPath)
+	})
+
+	list, err := f.c.ListImages(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	require.Len(t, list.Items, 3)
+
+	assert.Equal(t, "RegistryImageList", list.Kind)
+	assert.Equal(t, "kind-registry", list.Items[0].Registry)
+	assert.Equal(t, "my/app", list.Items[0].Repository)
+	assert.Equal(t, "latest", list.Items[0].Tag)
+	assert.Equal(t, "sha256:aaa", list.Items[0].Digest)
+	assert.Equal(t, int64(1100), list.Items[0].Size)
+	assert.Equal(t, created, list.Items[0].CreationTimestamp.UTC())
+	assert.Equal(t, time.Unix(1700000100, 0), list.Items[0].PushTimestamp.Time)
+
+	assert.Equal(t, "v1", list.Items[1].Tag)
+	assert.Equal(t, time.Unix(1700000000, 0), list.Items[1].PushTimestamp.Time)
+
+	assert.Equal(t, "other", list.Items[2].Repository)
+	assert.Equal(t, "v2", list.Items[2].Tag)
+	assert.True(t, list.Items[2].PushTimestamp.IsZero())
+}
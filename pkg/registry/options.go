@@ -2,30 +2,103 @@ package registry
 
 import (
 	"fmt"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/tilt-dev/ctlptl/pkg/api"
 )
 
 type ListOptions struct {
 	FieldSelector string
+
+	// Selects registries by the labels on the registry container.
+	LabelSelector string
 }
 
 type registryFields api.Registry
 
 func (cf *registryFields) Has(field string) bool {
-	return field == "name"
+	switch field {
+	case "name", "port", "image", "status.state", "network", "listenAddress":
+		return true
+	}
+	return false
 }
 
 func (cf *registryFields) Get(field string) string {
-	if field == "name" {
-		return (*api.Registry)(cf).Name
-	}
-	if field == "port" {
-		return fmt.Sprintf("%d", (*api.Registry)(cf).Port)
+	r := (*api.Registry)(cf)
+	switch field {
+	case "name":
+		return r.Name
+	case "port":
+		return fmt.Sprintf("%d", r.Port)
+	case "image":
+		return r.Status.Image
+	case "status.state":
+		return r.Status.State
+	case "listenAddress":
+		return r.Status.ListenAddress
 	}
 	return ""
 }
 
+// Some fields hold many values. A field matches if any value matches,
+// e.g., network=kind selects registries connected to the kind network.
+func (cf *registryFields) values(field string) ([]string, bool) {
+	r := (*api.Registry)(cf)
+	switch field {
+	case "network":
+		return r.Status.Networks, true
+	case "listenAddress":
+		if len(r.Status.ListenAddresses) > 0 {
+			return r.Status.ListenAddresses, true
+		}
+	}
+	return nil, false
+}
+
+// Checks the registry against each requirement of the selector.
+//
+// We can't use Selector.Matches() directly, because it
+// only knows how to compare single-valued fields.
+func (cf *registryFields) matches(selector fields.Selector) bool {
+	for _, req := range selector.Requirements() {
+		values, ok := cf.values(req.Field)
+		if !ok {
+			values = []string{cf.Get(req.Field)}
+		}
+		found := slices.Contains(values, req.Value)
+		if req.Operator == selection.NotEquals {
+			found = !found
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 var _ fields.Fields = &registryFields{}
+
+// Parses the selectors in the list options, and rejects fields
+// that we don't know how to select on.
+func parseSelectors(options ListOptions) (fields.Selector, labels.Selector, error) {
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, req := range fieldSelector.Requirements() {
+		if !(&registryFields{}).Has(req.Field) {
+			return nil, nil, fmt.Errorf("field selector %q: unsupported field %q", options.FieldSelector, req.Field)
+		}
+	}
+
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fieldSelector, labelSelector, nil
+}
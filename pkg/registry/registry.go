@@ -4,27 +4,37 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/distribution/reference"
 	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
 	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/client"
 	"github.com/phayes/freeport"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/tilt-dev/ctlptl/internal/dctr"
+	cexec "github.com/tilt-dev/ctlptl/internal/exec"
 	"github.com/tilt-dev/ctlptl/internal/socat"
 	"github.com/tilt-dev/ctlptl/pkg/api"
 	"github.com/tilt-dev/ctlptl/pkg/docker"
+	"github.com/tilt-dev/ctlptl/pkg/progress"
 )
 
 var (
@@ -38,6 +48,15 @@ const DefaultRegistryImageRef = "docker.io/library/registry:2" // The registry e
 // https://github.com/moby/moby/blob/v20.10.3/api/types/types.go#L313
 const containerStateRunning = "running"
 
+// How long to wait for a new registry to answer HTTP requests.
+const waitForReadyTimeout = 30 * time.Second
+
+// How long to wait for a single HTTP probe of the registry API.
+const probeTimeout = 2 * time.Second
+
+// Where the registry image stores its data.
+const registryStoragePath = "/var/lib/registry"
+
 // ctlptlLabels are labels applied on create to registry containers.
 //
 // These are not considered for equality purposes, as ctlptl supports interop
@@ -73,6 +92,15 @@ type Controller struct {
 	iostreams genericclioptions.IOStreams
 	dockerCLI dctr.CLI
 	socat     socatController
+	runner    cexec.CmdRunner
+	progress  progress.Reporter
+
+	// Checks that the registry API at the given URL is answering requests.
+	probe               func(ctx context.Context, baseURL string) error
+	waitForReadyTimeout time.Duration
+
+	// Whether Get and List probe each running registry.
+	probeOnList bool
 }
 
 func NewController(iostreams genericclioptions.IOStreams, dockerCLI dctr.CLI) *Controller {
@@ -80,6 +108,11 @@ func NewController(iostreams genericclioptions.IOStreams, dockerCLI dctr.CLI) *C
 		iostreams: iostreams,
 		dockerCLI: dockerCLI,
 		socat:     socat.NewController(dockerCLI),
+		runner:    cexec.RealCmdRunner{},
+		progress:  progress.Text,
+
+		probe:               probeHTTP,
+		waitForReadyTimeout: waitForReadyTimeout,
 	}
 }
 
@@ -93,9 +126,35 @@ func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, erro
 		iostreams: iostreams,
 		dockerCLI: dockerCLI,
 		socat:     socat.NewController(dockerCLI),
+		runner:    cexec.RealCmdRunner{},
+		progress:  progress.Text,
+
+		probe:               probeHTTP,
+		waitForReadyTimeout: waitForReadyTimeout,
 	}, nil
 }
 
+// Reports when registries are applied and deleted.
+func (c *Controller) SetProgress(r progress.Reporter) {
+	c.progress = r
+}
+
+// Probes the registry API of each running registry on Get and List,
+// to report whether it's ready.
+//
+// Off by default, because each probe can take up to probeTimeout,
+// and most callers only need the container state.
+func (c *Controller) SetProbeStatus(probe bool) {
+	c.probeOnList = probe
+}
+
+func (c *Controller) startPhase(phase progress.Phase, name string) func(err error) {
+	if c.progress == nil {
+		return progress.Text.Start(phase, name)
+	}
+	return c.progress.Start(phase, name)
+}
+
 func (c *Controller) Get(ctx context.Context, name string) (*api.Registry, error) {
 	list, err := c.List(ctx, ListOptions{FieldSelector: fmt.Sprintf("name=%s", name)})
 	if err != nil {
@@ -111,7 +170,7 @@ func (c *Controller) Get(ctx context.Context, name string) (*api.Registry, error
 }
 
 func (c *Controller) List(ctx context.Context, options ListOptions) (*api.RegistryList, error) {
-	selector, err := fields.ParseSelector(options.FieldSelector)
+	fieldSelector, labelSelector, err := parseSelectors(options)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +186,9 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Regist
 			continue
 		}
 		name := strings.TrimPrefix(container.Names[0], "/")
+		if !labelSelector.Matches(labels.Set(container.Labels)) {
+			continue
+		}
 		created := time.Unix(container.Created, 0)
 
 		inspect, err := c.dockerCLI.Client().ContainerInspect(ctx, container.ID, client.ContainerInspectOptions{})
@@ -134,22 +196,25 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Regist
 			return nil, err
 		}
 		env := inspect.Container.Config.Env
+		storage := storageFromHostConfig(inspect.Container.HostConfig)
 		netSummary := container.NetworkSettings
-		var ipAddress netip.Addr
+		var ipAddress, ipv6Address netip.Addr
 		networks := []string{}
 		if netSummary != nil {
 			for network := range netSummary.Networks {
 				networks = append(networks, network)
 			}
-			bridge, ok := netSummary.Networks["bridge"]
+			bridge, ok := netSummary.Networks[dctr.DefaultNetwork(c.dockerCLI.Client())]
 			if ok && bridge != nil {
 				ipAddress = bridge.IPAddress
+				ipv6Address = bridge.GlobalIPv6Address
 			}
 		}
 		sort.Strings(networks)
 
 		var warnings []string
-		listenAddress, hostPort, containerPort, err := c.ipAndPortsFrom(container.Ports)
+		listenAddress, listenAddresses, hostPort, containerPort, err := c.ipAndPortsFrom(
+			container.Ports, containerPortFromContainer(container.Labels))
 		if err != nil {
 			warnings = append(warnings, fmt.Sprintf("Unexpected registry ports: %+v", container.Ports))
 		}
@@ -161,22 +226,35 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Regist
 			Status: api.RegistryStatus{
 				CreationTimestamp: metav1.Time{Time: created},
 				ContainerID:       container.ID,
-				IPAddress:         ipAddress.String(),
 				HostPort:          hostPort,
 				ListenAddress:     listenAddress,
+				ListenAddresses:   listenAddresses,
 				ContainerPort:     containerPort,
+				Flavor:            flavorFromContainer(container.Labels, container.Image),
 				Networks:          networks,
 				State:             string(container.State),
 				Labels:            container.Labels,
 				Image:             container.Image,
 				Env:               env,
+				Storage:           storage,
 				Warnings:          warnings,
 			},
 		}
+		if ipAddress.IsValid() {
+			registry.Status.IPAddress = ipAddress.String()
+		}
+		if ipv6Address.IsValid() {
+			registry.Status.IPv6Address = ipv6Address.String()
+		}
 
-		if !selector.Matches((*registryFields)(registry)) {
+		if !(*registryFields)(registry).matches(fieldSelector) {
 			continue
 		}
+
+		// Probe after filtering, so that we only wait on the registries we return.
+		if c.probeOnList && registry.Status.State == containerStateRunning {
+			c.probeStatus(ctx, registry)
+		}
 		result = append(result, *registry)
 	}
 	return &api.RegistryList{
@@ -185,33 +263,115 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Regist
 	}, nil
 }
 
-func (c *Controller) ipAndPortsFrom(ports []container.PortSummary) (listenAddress string, hostPort int, containerPort int, err error) {
+// Reads the persistent storage that ctlptl mounted at the registry storage path.
+//
+// Anonymous volumes aren't in the HostConfig, so they're ignored.
+func storageFromHostConfig(hostConfig *container.HostConfig) *api.RegistryStorage {
+	if hostConfig == nil {
+		return nil
+	}
+	for _, m := range hostConfig.Mounts {
+		if m.Target != registryStoragePath {
+			continue
+		}
+		switch m.Type {
+		case mount.TypeVolume:
+			return &api.RegistryStorage{Volume: m.Source}
+		case mount.TypeBind:
+			return &api.RegistryStorage{HostPath: m.Source}
+		}
+	}
+	return nil
+}
+
+// Finds the host addresses and ports that the registry is published on.
+//
+// The listen address prefers IPv4, for compatibility with tools
+// that don't expect IPv6.
+func (c *Controller) ipAndPortsFrom(ports []container.PortSummary, registryPort int) (listenAddress string, listenAddresses []string, hostPort int, containerPort int, err error) {
+	addrs := []netip.Addr{}
 	for _, port := range ports {
-		if port.PrivatePort == 5000 {
-			return port.IP.String(), int(port.PublicPort), int(port.PrivatePort), nil
+		if int(port.PrivatePort) != registryPort {
+			continue
+		}
+		if hostPort == 0 {
+			hostPort, containerPort = int(port.PublicPort), int(port.PrivatePort)
 		}
+		if !slices.Contains(addrs, port.IP) {
+			addrs = append(addrs, port.IP)
+		}
+	}
+	if hostPort == 0 {
+		return "", nil, 0, 0, fmt.Errorf("could not find registry port")
+	}
+
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return addrs[i].Is4() && !addrs[j].Is4()
+	})
+	for _, addr := range addrs {
+		listenAddresses = append(listenAddresses, addr.String())
 	}
-	return "", 0, 0, fmt.Errorf("could not find registry port")
+	return listenAddresses[0], listenAddresses, hostPort, containerPort, nil
 }
 
 // Compare the desired registry against the existing registry, and reconcile
 // the two to match.
 func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Registry, error) {
 	FillDefaults(desired)
+	done := c.startPhase(progress.PhaseRegistryApply, desired.Name)
+	result, err := c.apply(ctx, desired)
+	done(err)
+	return result, err
+}
+
+func (c *Controller) apply(ctx context.Context, desired *api.Registry) (*api.Registry, error) {
+	err := validateFlavor(desired.Flavor)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s: %v", desired.Name, err)
+	}
+	if desired.ContainerPort < 0 || desired.ContainerPort > 65535 {
+		return nil, fmt.Errorf("registry %s: invalid container port %d", desired.Name, desired.ContainerPort)
+	}
+	if desired.Storage != nil && desired.Storage.Volume != "" && desired.Storage.HostPath != "" {
+		return nil, fmt.Errorf("registry %s: storage may specify a volume or a hostPath, but not both", desired.Name)
+	}
+	if desired.Storage != nil && desired.Storage.HostPath != "" {
+		hostPath, err := filepath.Abs(desired.Storage.HostPath)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: storage host path: %v", desired.Name, err)
+		}
+		desired.Storage.HostPath = hostPath
+	}
+
 	existing, err := c.Get(ctx, desired.Name)
 	if err != nil && !errors.IsNotFound(err) {
 		return nil, err
 	}
 
 	if existing == nil {
+		// We only list containers with the ctlptl role label. Don't
+		// clobber a container that someone else created with this name.
+		err := c.checkNameAvailable(ctx, desired.Name)
+		if err != nil {
+			return nil, err
+		}
 		existing = &api.Registry{}
 	}
 
+	flavor := c.flavorConfig(existing, desired)
+	containerPort := c.containerPortConfig(existing, desired)
+
 	needsDelete := false
 	if existing.Port != 0 && desired.Port != 0 && existing.Port != desired.Port {
 		// If the port has changed, let's delete the registry and recreate it.
 		needsDelete = true
 	}
+	// If the flavor or the container port has changed, the registry
+	// needs a different configuration, so we need to re-create it.
+	if existing.Status.ContainerID != "" &&
+		(existing.Status.Flavor != flavor || existing.Status.ContainerPort != containerPort) {
+		needsDelete = true
+	}
 	// If the desired image is different
 	// from the existing image, we need
 	// to delete the registry and recreate it.
@@ -219,6 +379,16 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 		!imagesRefsEqual(existing.Status.Image, desired.Image) {
 		needsDelete = true
 	}
+	// If the registry should be dual-stack, but isn't published on both
+	// IPv4 and IPv6, we need to re-create it to publish the ports.
+	if desired.DualStack && existing.Status.ContainerID != "" && !isDualStack(existing) {
+		needsDelete = true
+	}
+	// If the desired storage is different from the existing storage,
+	// we need to re-create the registry to mount it.
+	if desired.Storage != nil && !reflect.DeepEqual(existing.Status.Storage, desired.Storage) {
+		needsDelete = true
+	}
 	if existing.Status.State != containerStateRunning {
 		// If the registry has died, we need to recreate.
 		needsDelete = true
@@ -254,9 +424,17 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 			}
 		}
 	}
-	if _, ok := desiredEnvs["REGISTRY_STORAGE_DELETE_ENABLED"]; !ok {
-		desiredEnvs["REGISTRY_STORAGE_DELETE_ENABLED"] = "true"
-		desired.Env = append(desired.Env, "REGISTRY_STORAGE_DELETE_ENABLED=true")
+	defaultEnv := defaultEnvForFlavor(flavor, containerPort)
+	defaultEnvKeys := make([]string, 0, len(defaultEnv))
+	for k := range defaultEnv {
+		defaultEnvKeys = append(defaultEnvKeys, k)
+	}
+	sort.Strings(defaultEnvKeys)
+	for _, k := range defaultEnvKeys {
+		if _, ok := desiredEnvs[k]; !ok {
+			desiredEnvs[k] = defaultEnv[k]
+			desired.Env = append(desired.Env, fmt.Sprintf("%s=%s", k, defaultEnv[k]))
+		}
 	}
 	if eq := reflect.DeepEqual(desiredEnvs, existingEnvs); !eq {
 		needsDelete = true
@@ -273,7 +451,7 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 
 	if existing.Status.ContainerID != "" {
 		// If we got to this point, and the container id exists, then the registry is up to date!
-		return existing, nil
+		return c.waitForReady(ctx, existing)
 	}
 
 	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Creating registry %q...\n", desired.Name)
@@ -283,12 +461,21 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 		return nil, err
 	}
 
-	exposedPorts, portBindings, hostPort, err := c.portConfigs(existing, desired)
+	exposedPorts, portBindings, hostPort, err := c.portConfigs(existing, desired, containerPort)
 	if err != nil {
 		return nil, err
 	}
 
-	image := c.imageConfig(existing, desired)
+	image := c.imageConfig(existing, desired, flavor)
+
+	labels := c.labelConfigs(existing, desired)
+	labels[docker.ContainerLabelRegistryFlavor] = flavor
+	labels[docker.ContainerLabelRegistryContainerPort] = strconv.Itoa(containerPort)
+
+	mounts, err := c.mountConfigs(existing, desired)
+	if err != nil {
+		return nil, err
+	}
 
 	err = dctr.Run(
 		ctx,
@@ -298,12 +485,13 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 			Hostname:     desired.Name,
 			Image:        image,
 			ExposedPorts: exposedPorts,
-			Labels:       c.labelConfigs(existing, desired),
+			Labels:       labels,
 			Env:          desired.Env,
 		},
 		&container.HostConfig{
 			RestartPolicy: container.RestartPolicy{Name: "always"},
 			PortBindings:  portBindings,
+			Mounts:        mounts,
 		},
 		&network.NetworkingConfig{})
 	if err != nil {
@@ -315,11 +503,72 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Reg
 		return nil, err
 	}
 
-	return c.Get(ctx, desired.Name)
+	applied, err := c.Get(ctx, desired.Name)
+	if err != nil {
+		return nil, err
+	}
+	return c.waitForReady(ctx, applied)
+}
+
+// Probes the registry API on the host port, and records the result in the status.
+func (c *Controller) probeStatus(ctx context.Context, registry *api.Registry) {
+	err := func() error {
+		baseURL, err := hostURL(registry, c.dockerCLI.Client().DaemonHost())
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+		return c.probe(ctx, baseURL)
+	}()
+
+	registry.Status.Ready = err == nil
+	registry.Status.LastProbeError = ""
+	if err != nil {
+		registry.Status.LastProbeError = err.Error()
+	}
+}
+
+// The registry container may take a moment to start listening,
+// so poll the registry API until it answers.
+func (c *Controller) waitForReady(ctx context.Context, registry *api.Registry) (*api.Registry, error) {
+	if registry.Status.Ready {
+		return registry, nil
+	}
+
+	// The probe dials the registry's host port on localhost,
+	// which we can't reach from inside a container.
+	if dctr.InsideContainer(ctx, c.dockerCLI.Client()) != "" {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+			"Warning: running inside a container, so can't check that registry %q started\n", registry.Name)
+		return registry, nil
+	}
+
+	// Most of the time, the registry is already up.
+	registry = registry.DeepCopy()
+	c.probeStatus(ctx, registry)
+	if registry.Status.Ready {
+		return registry, nil
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Waiting %s for registry %q to start...\n",
+		duration.ShortHumanDuration(c.waitForReadyTimeout), registry.Name)
+	err := wait.PollUntilContextTimeout(ctx, 250*time.Millisecond, c.waitForReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		c.probeStatus(ctx, registry)
+		return registry.Status.Ready, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timed out waiting for registry %s to start: %s", registry.Name, registry.Status.LastProbeError)
+	}
+	return registry, nil
+}
+
+func probeHTTP(ctx context.Context, baseURL string) error {
+	return newAPIClient(baseURL).ping(ctx)
 }
 
 // Compute the ports to ContainerCreate() call
-func (c *Controller) portConfigs(existing *api.Registry, desired *api.Registry) (network.PortSet, network.PortMap, int, error) {
+func (c *Controller) portConfigs(existing *api.Registry, desired *api.Registry, containerPort int) (network.PortSet, network.PortMap, int, error) {
 	// Preserve existing address by default
 	hostPort := existing.Status.HostPort
 	listenAddress := existing.Status.ListenAddress
@@ -347,25 +596,65 @@ func (c *Controller) portConfigs(existing *api.Registry, desired *api.Registry)
 		listenAddress = "127.0.0.1"
 	}
 
-	hostIP, err := netip.ParseAddr(listenAddress)
+	hostIP, err := netip.ParseAddr(strings.Trim(listenAddress, "[]"))
 	if err != nil {
 		return nil, nil, 0, fmt.Errorf("parsing listen address %q: %v", listenAddress, err)
 	}
-	port := network.MustParsePort("5000/tcp")
+	hostIPs := []netip.Addr{hostIP}
+
+	// Preserve dual-stack by default.
+	if desired.DualStack || isDualStack(existing) {
+		hostIPs, err = dualStackAddrs(hostIP)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("registry %s: %v", desired.Name, err)
+		}
+	}
+
+	port := network.MustParsePort(fmt.Sprintf("%d/tcp", containerPort))
 	portSet := network.PortSet{
 		port: struct{}{},
 	}
+	bindings := []network.PortBinding{}
+	for _, ip := range hostIPs {
+		bindings = append(bindings, network.PortBinding{
+			HostIP:   ip,
+			HostPort: fmt.Sprintf("%d", hostPort),
+		})
+	}
 	portMap := network.PortMap{
-		port: []network.PortBinding{
-			{
-				HostIP:   hostIP,
-				HostPort: fmt.Sprintf("%d", hostPort),
-			},
-		},
+		port: bindings,
 	}
 	return portSet, portMap, hostPort, nil
 }
 
+// Pairs a listen address with its counterpart in the other IP family.
+func dualStackAddrs(addr netip.Addr) ([]netip.Addr, error) {
+	switch {
+	case addr.IsLoopback():
+		return []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.IPv6Loopback()}, nil
+	case addr.IsUnspecified():
+		return []netip.Addr{netip.IPv4Unspecified(), netip.IPv6Unspecified()}, nil
+	}
+	return nil, fmt.Errorf("dualStack requires a loopback or wildcard listen address, got %s", addr)
+}
+
+// Checks whether the registry is published on both IPv4 and IPv6.
+func isDualStack(r *api.Registry) bool {
+	hasV4, hasV6 := false, false
+	for _, a := range r.Status.ListenAddresses {
+		addr, err := netip.ParseAddr(a)
+		if err != nil {
+			continue
+		}
+		if addr.Is4() {
+			hasV4 = true
+		} else {
+			hasV6 = true
+		}
+	}
+	return hasV4 && hasV6
+}
+
 // Compute the label configs to the container create call.
 func (c *Controller) labelConfigs(existing *api.Registry, desired *api.Registry) map[string]string {
 	newLabels := make(map[string]string, len(existing.Status.Labels)+len(desired.Labels)+len(ctlptlLabels))
@@ -400,18 +689,78 @@ func (c *Controller) labelConfigs(existing *api.Registry, desired *api.Registry)
 }
 
 // Compute the image to ContainerCreate() call
-func (c *Controller) imageConfig(existing *api.Registry, desired *api.Registry) string {
+func (c *Controller) imageConfig(existing *api.Registry, desired *api.Registry, flavor string) string {
 	// Desired image takes precedence.
 	if desired.Image != "" {
 		return desired.Image
 	}
 
 	// Preserve existing image when possible.
-	if existing.Status.Image != "" {
+	if existing.Status.Image != "" && existing.Status.Flavor == flavor {
 		return existing.Status.Image
 	}
 
-	return DefaultRegistryImageRef
+	return defaultImageForFlavor(flavor)
+}
+
+// Compute the registry flavor, preserving the existing flavor by default.
+func (c *Controller) flavorConfig(existing *api.Registry, desired *api.Registry) string {
+	if desired.Flavor != "" {
+		return desired.Flavor
+	}
+	if existing.Status.Flavor != "" {
+		return existing.Status.Flavor
+	}
+	return FlavorDistribution
+}
+
+// Compute the in-container port, preserving the existing port by default.
+func (c *Controller) containerPortConfig(existing *api.Registry, desired *api.Registry) int {
+	if desired.ContainerPort != 0 {
+		return desired.ContainerPort
+	}
+	if existing.Status.ContainerPort != 0 {
+		return existing.Status.ContainerPort
+	}
+	return defaultContainerPort
+}
+
+// Compute the storage mounts to ContainerCreate() call
+func (c *Controller) mountConfigs(existing *api.Registry, desired *api.Registry) ([]mount.Mount, error) {
+	// Desired storage takes precedence, but preserve the existing storage
+	// so that we don't lose images when re-creating for other reasons.
+	storage := desired.Storage
+	if storage == nil {
+		storage = existing.Status.Storage
+	}
+	if storage == nil {
+		return nil, nil
+	}
+
+	if storage.Volume != "" {
+		return []mount.Mount{{
+			Type:   mount.TypeVolume,
+			Source: storage.Volume,
+			Target: registryStoragePath,
+		}}, nil
+	}
+
+	if storage.HostPath != "" {
+		// Docker refuses to bind-mount a directory that doesn't exist.
+		// We can only create it if Docker is running on this machine.
+		if docker.IsLocalHost(c.dockerCLI.Client().DaemonHost()) {
+			err := os.MkdirAll(storage.HostPath, 0755)
+			if err != nil {
+				return nil, fmt.Errorf("creating registry storage %s: %v", storage.HostPath, err)
+			}
+		}
+		return []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: storage.HostPath,
+			Target: registryStoragePath,
+		}}, nil
+	}
+	return nil, nil
 }
 
 func (c *Controller) maybeCreateForwarder(ctx context.Context, port int) error {
@@ -424,9 +773,8 @@ func (c *Controller) maybeCreateForwarder(ctx context.Context, port int) error {
 	return c.socat.ConnectRemoteDockerPort(ctx, port)
 }
 
+// Finds the containers that ctlptl manages as registries.
 func (c *Controller) registryContainers(ctx context.Context) ([]container.Summary, error) {
-	containers := make(map[string]container.Summary)
-
 	filters := client.Filters{}
 	filters.Add("label", fmt.Sprintf("%s=registry", docker.ContainerLabelRole))
 	roleContainers, err := c.dockerCLI.Client().ContainerList(ctx, client.ContainerListOptions{
@@ -436,33 +784,28 @@ func (c *Controller) registryContainers(ctx context.Context) ([]container.Summar
 	if err != nil {
 		return nil, err
 	}
-	for _, c := range roleContainers.Items {
-		containers[c.ID] = c
-	}
 
-	filters = client.Filters{}
-	filters.Add("ancestor", DefaultRegistryImageRef)
-	ancestorContainers, err := c.dockerCLI.Client().ContainerList(ctx, client.ContainerListOptions{
-		Filters: filters,
-		All:     true,
-	})
-	if err != nil {
-		return nil, err
-	}
-	for _, c := range ancestorContainers.Items {
-		containers[c.ID] = c
-	}
-
-	result := make([]container.Summary, 0, len(containers))
-	for _, c := range containers {
-		result = append(result, c)
-	}
+	result := roleContainers.Items
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].ID < result[j].ID
 	})
 	return result, nil
 }
 
+// Checks that no container without the ctlptl role label has the registry's name.
+func (c *Controller) checkNameAvailable(ctx context.Context, name string) error {
+	_, err := c.dockerCLI.Client().ContainerInspect(ctx, name, client.ContainerInspectOptions{})
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return fmt.Errorf("registry %s: a container named %s already exists, but ctlptl didn't create it "+
+		"(it has no %s=registry label). Delete the container, or choose another registry name",
+		name, name, docker.ContainerLabelRole)
+}
+
 // Delete the given registry.
 func (c *Controller) Delete(ctx context.Context, name string) error {
 	registry, err := c.Get(ctx, name)
@@ -475,9 +818,63 @@ func (c *Controller) Delete(ctx context.Context, name string) error {
 		return fmt.Errorf("container not running registry: %s", name)
 	}
 
+	done := c.startPhase(progress.PhaseRegistryDelete, name)
 	_, err = c.dockerCLI.Client().ContainerRemove(ctx, registry.Status.ContainerID, client.ContainerRemoveOptions{
 		Force: true,
 	})
+	done(err)
+	return err
+}
+
+// Stop the given registry, without deleting its container.
+func (c *Controller) Stop(ctx context.Context, name string) error {
+	registry, err := c.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if registry.Status.State != containerStateRunning {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Stopping registry %q...\n", name)
+	_, err = c.dockerCLI.Client().ContainerStop(ctx, registry.Status.ContainerID, client.ContainerStopOptions{})
+	return err
+}
+
+// Start a stopped registry, and wait for it to be ready.
+func (c *Controller) Start(ctx context.Context, name string) (*api.Registry, error) {
+	registry, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if registry.Status.State != containerStateRunning {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Starting registry %q...\n", name)
+		_, err = c.dockerCLI.Client().ContainerStart(ctx, registry.Status.ContainerID, client.ContainerStartOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		// Stopped containers don't report their published ports,
+		// so re-read the registry to find them.
+		registry, err = c.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.maybeCreateForwarder(ctx, registry.Status.HostPort)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.waitForReady(ctx, registry)
+}
+
+// Delete the Docker volume that a registry used for storage.
+//
+// Should only be called after the registry itself has been deleted.
+func (c *Controller) DeleteVolume(ctx context.Context, volume string) error {
+	_, err := c.dockerCLI.Client().VolumeRemove(ctx, volume, client.VolumeRemoveOptions{})
 	return err
 }
 
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/netip"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/distribution/reference"
 	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
 	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/client"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +21,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/tilt-dev/ctlptl/internal/dctr"
+	cexec "github.com/tilt-dev/ctlptl/internal/exec"
 	"github.com/tilt-dev/ctlptl/pkg/api"
 )
 
@@ -140,11 +144,16 @@ func registryBadPorts() container.Summary {
 func TestListRegistries(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
+	f.c.SetProbeStatus(true)
 
+	// Registries are discovered by the role label, so registries
+	// that ctlptl didn't create are ignored.
 	regWithoutLabels := kindRegistryLoopback()
+	regWithoutLabels.ID = "e36f5a5e6b7e1c8c6a2f1e8ef6d1cc2e1d0e9b7b8c3c5a3f7e2e3d8a1b2c3d4e"
+	regWithoutLabels.Names = []string{"/unmanaged-registry"}
 	regWithoutLabels.Labels = nil
 
-	f.docker.containers = []container.Summary{kindRegistry(), regWithoutLabels, kindRegistryCustomImage()}
+	f.docker.containers = []container.Summary{kindRegistry(), kindRegistryLoopback(), regWithoutLabels, kindRegistryCustomImage()}
 
 	list, err := f.c.List(context.Background(), ListOptions{})
 	require.NoError(t, err)
@@ -160,14 +169,17 @@ func TestListRegistries(t *testing.T) {
 			CreationTimestamp: metav1.Time{Time: time.Unix(1603483645, 0)},
 			HostPort:          5001,
 			ContainerPort:     5000,
+			Flavor:            "distribution",
 			IPAddress:         "172.0.1.2",
 			ListenAddress:     "127.0.0.1",
+			ListenAddresses:   []string{"127.0.0.1"},
 			Networks:          []string{"bridge", "kind"},
 			ContainerID:       "a815c0ec15f1f7430bd402e3fffe65026dd692a1a99861a52b3e30ad6e253a08",
 			State:             "running",
 			Labels:            map[string]string{"dev.tilt.ctlptl.role": "registry"},
 			Image:             DefaultRegistryImageRef,
 			Env:               []string{"REGISTRY_STORAGE_DELETE_ENABLED=true", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Ready:             true,
 		},
 	}, list.Items[0])
 	assert.Equal(t, api.Registry{
@@ -178,14 +190,17 @@ func TestListRegistries(t *testing.T) {
 			CreationTimestamp: metav1.Time{Time: time.Unix(1603483647, 0)},
 			HostPort:          5001,
 			ContainerPort:     5000,
+			Flavor:            "distribution",
 			IPAddress:         "172.0.1.2",
 			ListenAddress:     "127.0.0.1",
+			ListenAddresses:   []string{"127.0.0.1"},
 			Networks:          []string{"bridge", "kind"},
 			ContainerID:       "c7f123e65474f951c3bc4232c888616c0f9b1052c7ae706a3b6d4701bea6e90d",
 			State:             "running",
 			Labels:            map[string]string{"dev.tilt.ctlptl.role": "registry"},
 			Image:             "fake.tilt.dev/my-registry-image:latest",
 			Env:               []string{"REGISTRY_STORAGE_DELETE_ENABLED=true", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Ready:             true,
 		},
 	}, list.Items[1])
 	assert.Equal(t, api.Registry{
@@ -196,23 +211,65 @@ func TestListRegistries(t *testing.T) {
 			CreationTimestamp: metav1.Time{Time: time.Unix(1603483646, 0)},
 			HostPort:          5001,
 			ContainerPort:     5000,
+			Flavor:            "distribution",
 			IPAddress:         "172.0.1.2",
 			ListenAddress:     "127.0.0.1",
+			ListenAddresses:   []string{"127.0.0.1"},
 			Networks:          []string{"bridge", "kind"},
 			ContainerID:       "d62f2587ff7b03858f144d3cf83c789578a6d6403f8b82a459ab4e317917cd42",
 			State:             "running",
+			Labels:            map[string]string{"dev.tilt.ctlptl.role": "registry"},
 			Image:             DefaultRegistryImageRef,
 			Env:               []string{"REGISTRY_STORAGE_DELETE_ENABLED=true", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Ready:             true,
 		},
 	}, list.Items[2])
 }
 
-func TestListRegistries_badPorts(t *testing.T) {
+func TestListRegistriesSelectors(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
 
-	regWithoutLabels := kindRegistryLoopback()
-	regWithoutLabels.Labels = nil
+	k3d := kindRegistryCustomImage()
+	k3d.Labels = map[string]string{"dev.tilt.ctlptl.role": "registry", "app": "k3d"}
+	k3d.NetworkSettings.Networks = map[string]*network.EndpointSettings{
+		"bridge": &network.EndpointSettings{IPAddress: netip.MustParseAddr("172.0.1.4")},
+		"k3d":    &network.EndpointSettings{IPAddress: netip.MustParseAddr("172.0.2.2")},
+	}
+	exited := kindRegistryLoopback()
+	exited.State = "exited"
+	exited.Ports = nil
+	f.docker.containers = []container.Summary{kindRegistry(), exited, k3d}
+
+	names := func(options ListOptions) []string {
+		list, err := f.c.List(context.Background(), options)
+		require.NoError(t, err)
+		result := []string{}
+		for _, item := range list.Items {
+			result = append(result, item.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"kind-registry-custom-image"}, names(ListOptions{LabelSelector: "app=k3d"}))
+	assert.Equal(t, []string{"kind-registry", "kind-registry-loopback"}, names(ListOptions{LabelSelector: "app!=k3d"}))
+	assert.Equal(t, []string{"kind-registry-loopback"}, names(ListOptions{FieldSelector: "status.state!=running"}))
+	assert.Equal(t, []string{"kind-registry-custom-image"}, names(ListOptions{FieldSelector: "image=fake.tilt.dev/my-registry-image:latest"}))
+	assert.Equal(t, []string{"kind-registry", "kind-registry-loopback"}, names(ListOptions{FieldSelector: "network=kind"}))
+	assert.Equal(t, []string{"kind-registry-custom-image"}, names(ListOptions{FieldSelector: "network!=kind"}))
+	assert.Equal(t, []string{"kind-registry", "kind-registry-custom-image"}, names(ListOptions{FieldSelector: "listenAddress=127.0.0.1,port=5001"}))
+	assert.Equal(t, []string{}, names(ListOptions{FieldSelector: "network=kind", LabelSelector: "app=k3d"}))
+
+	_, err := f.c.List(context.Background(), ListOptions{FieldSelector: "status.color=blue"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unsupported field "status.color"`)
+	}
+}
+
+func TestListRegistries_badPorts(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.c.SetProbeStatus(true)
 
 	f.docker.containers = []container.Summary{registryBadPorts()}
 
@@ -232,6 +289,8 @@ func TestListRegistries_badPorts(t *testing.T) {
 			Labels:            map[string]string{"dev.tilt.ctlptl.role": "registry"},
 			Image:             DefaultRegistryImageRef,
 			Env:               []string{"REGISTRY_STORAGE_DELETE_ENABLED=true", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Flavor:            "distribution",
+			LastProbeError:    "registry kind-registry is not listening on a host port",
 			Warnings: []string{
 				"Unexpected registry ports: [{IP:127.0.0.1 PrivatePort:5001 PublicPort:5002 Type:tcp}]",
 			},
@@ -242,6 +301,7 @@ func TestListRegistries_badPorts(t *testing.T) {
 func TestGetRegistry(t *testing.T) {
 	f := newFixture(t)
 	defer f.TearDown()
+	f.c.SetProbeStatus(true)
 
 	f.docker.containers = []container.Summary{kindRegistry()}
 
@@ -255,14 +315,17 @@ func TestGetRegistry(t *testing.T) {
 			CreationTimestamp: metav1.Time{Time: time.Unix(1603483645, 0)},
 			HostPort:          5001,
 			ContainerPort:     5000,
+			Flavor:            "distribution",
 			IPAddress:         "172.0.1.2",
 			ListenAddress:     "127.0.0.1",
+			ListenAddresses:   []string{"127.0.0.1"},
 			Networks:          []string{"bridge", "kind"},
 			ContainerID:       "a815c0ec15f1f7430bd402e3fffe65026dd692a1a99861a52b3e30ad6e253a08",
 			State:             "running",
 			Labels:            map[string]string{"dev.tilt.ctlptl.role": "registry"},
 			Image:             DefaultRegistryImageRef,
 			Env:               []string{"REGISTRY_STORAGE_DELETE_ENABLED=true", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Ready:             true,
 		},
 	}, registry)
 }
@@ -305,8 +368,10 @@ func TestApplyLabels(t *testing.T) {
 	config := f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
 		assert.Equal(t, map[string]string{
-			"managed-by":           "ctlptl",
-			"dev.tilt.ctlptl.role": "registry",
+			"managed-by":                             "ctlptl",
+			"dev.tilt.ctlptl.role":                   "registry",
+			"dev.tilt.ctlptl.registry.flavor":        "distribution",
+			"dev.tilt.ctlptl.registry.containerPort": "5000",
 		}, config.Labels)
 		assert.Equal(t, "kind-registry", config.Hostname)
 		assert.Equal(t, DefaultRegistryImageRef, config.Image)
@@ -333,7 +398,9 @@ func TestStripDesktopLabels(t *testing.T) {
 	config := f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
 		assert.Equal(t, map[string]string{
-			"dev.tilt.ctlptl.role": "registry",
+			"dev.tilt.ctlptl.role":                   "registry",
+			"dev.tilt.ctlptl.registry.flavor":        "distribution",
+			"dev.tilt.ctlptl.registry.containerPort": "5000",
 		}, config.Labels)
 	}
 }
@@ -357,7 +424,7 @@ func TestPreservePort(t *testing.T) {
 
 	config := f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
-		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry"}, config.Labels)
+		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry", "dev.tilt.ctlptl.registry.flavor": "distribution", "dev.tilt.ctlptl.registry.containerPort": "5000"}, config.Labels)
 		assert.Equal(t, "kind-registry", config.Hostname)
 		assert.Equal(t, DefaultRegistryImageRef, config.Image)
 	}
@@ -392,7 +459,7 @@ func TestCustomImage(t *testing.T) {
 	}
 	config := f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
-		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry"}, config.Labels)
+		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry", "dev.tilt.ctlptl.registry.flavor": "distribution", "dev.tilt.ctlptl.registry.containerPort": "5000"}, config.Labels)
 		assert.Equal(t, "kind-registry", config.Hostname)
 		assert.Equal(t, "fake.tilt.dev/different-registry-image:latest", config.Image)
 	}
@@ -410,8 +477,10 @@ func TestCustomImage(t *testing.T) {
 	config = f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
 		assert.Equal(t, map[string]string{
-			"dev.tilt.ctlptl.role": "registry",
-			"extra-label":          "ctlptl",
+			"dev.tilt.ctlptl.role":                   "registry",
+			"dev.tilt.ctlptl.registry.flavor":        "distribution",
+			"dev.tilt.ctlptl.registry.containerPort": "5000",
+			"extra-label":                            "ctlptl",
 		}, config.Labels)
 		assert.Equal(t, "kind-registry", config.Hostname)
 		assert.Equal(t, "fake.tilt.dev/different-registry-image:latest", config.Image)
@@ -448,13 +517,475 @@ func TestCustomEnv(t *testing.T) {
 	}
 	config := f.docker.lastCreateConfig
 	if assert.NotNil(t, config) {
-		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry"}, config.Labels)
+		assert.Equal(t, map[string]string{"dev.tilt.ctlptl.role": "registry", "dev.tilt.ctlptl.registry.flavor": "distribution", "dev.tilt.ctlptl.registry.containerPort": "5000"}, config.Labels)
 		assert.Equal(t, "kind-registry", config.Hostname)
 		assert.Equal(t, DefaultRegistryImageRef, config.Image)
 		assert.Equal(t, []string{"REGISTRY_STORAGE_DELETE_ENABLED=false"}, config.Env)
 	}
 }
 
+func TestApplyStorageVolume(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	storage := &api.RegistryStorage{Volume: "kind-registry-data"}
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Storage:  storage,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, storage, registry.Status.Storage)
+	}
+	assert.Equal(t, "a815c0ec15f1f7430bd402e3fffe65026dd692a1a99861a52b3e30ad6e253a08", f.docker.lastRemovedContainer)
+	hostConfig := f.docker.lastCreateHostConfig
+	if assert.NotNil(t, hostConfig) {
+		assert.Equal(t, []mount.Mount{{
+			Type:   mount.TypeVolume,
+			Source: "kind-registry-data",
+			Target: "/var/lib/registry",
+		}}, hostConfig.Mounts)
+	}
+
+	// Re-applying the same storage shouldn't re-create the registry.
+	f.docker.lastCreateHostConfig = nil
+	_, err = f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Storage:  storage,
+	})
+	if assert.NoError(t, err) {
+		assert.Nil(t, f.docker.lastCreateHostConfig, "Registry should not have been re-created")
+	}
+}
+
+func TestPreserveStorage(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	existing := kindRegistry()
+	f.docker.containers = []container.Summary{existing}
+	f.docker.mounts = map[string][]mount.Mount{
+		existing.ID: {{Type: mount.TypeBind, Source: "/tmp/registry-data", Target: "/var/lib/registry"}},
+	}
+
+	// Changing the labels re-creates the registry,
+	// but the images should stay where they were.
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Labels:   map[string]string{"extra-label": "ctlptl"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, &api.RegistryStorage{HostPath: "/tmp/registry-data"}, registry.Status.Storage)
+	}
+	hostConfig := f.docker.lastCreateHostConfig
+	if assert.NotNil(t, hostConfig) {
+		assert.Equal(t, []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: "/tmp/registry-data",
+			Target: "/var/lib/registry",
+		}}, hostConfig.Mounts)
+	}
+}
+
+func TestApplyStorageInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Storage:  &api.RegistryStorage{Volume: "kind-registry-data", HostPath: "/tmp/registry-data"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "storage may specify a volume or a hostPath, but not both")
+	}
+}
+
+func TestApplyWaitsForReady(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	// Refuse the first few probes, as if the registry were still starting.
+	probeCount := 0
+	f.c.probe = func(ctx context.Context, baseURL string) error {
+		probeCount++
+		if probeCount < 3 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+	})
+	require.NoError(t, err)
+	assert.True(t, registry.Status.Ready)
+	assert.Equal(t, "", registry.Status.LastProbeError)
+	assert.Equal(t, 3, probeCount)
+}
+
+func TestApplyReadyTimeout(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+	f.probeErr = fmt.Errorf("connection refused")
+	f.c.waitForReadyTimeout = 500 * time.Millisecond
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out waiting for registry kind-registry to start: connection refused")
+	assert.Equal(t, "http://127.0.0.1:5001", f.probes[0])
+}
+
+func TestApplyInsideContainerSkipsProbe(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+	f.docker.containerID = "0123456789ab"
+	f.probeErr = fmt.Errorf("connection refused")
+
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+	})
+	require.NoError(t, err)
+	assert.False(t, registry.Status.Ready)
+}
+
+func TestListProbesOnlyMatches(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.c.SetProbeStatus(true)
+
+	f.docker.containers = []container.Summary{kindRegistry(), kindRegistryCustomImage()}
+
+	list, err := f.c.List(context.Background(), ListOptions{FieldSelector: "name=kind-registry"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Items))
+	assert.Equal(t, []string{"http://127.0.0.1:5001"}, f.probes)
+}
+
+func TestListSkipsProbeByDefault(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	list, err := f.c.List(context.Background(), ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(list.Items))
+	assert.False(t, list.Items[0].Status.Ready)
+	assert.Equal(t, 0, len(f.probes))
+}
+
+func TestStopRegistry(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	err := f.c.Stop(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, kindRegistry().ID, f.docker.lastStoppedContainer)
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "exited", registry.Status.State)
+}
+
+func TestStartRegistry(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	stopped := kindRegistry()
+	stopped.State = "exited"
+	f.docker.containers = []container.Summary{stopped}
+
+	registry, err := f.c.Start(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, stopped.ID, f.docker.lastStartedContainer)
+	assert.Equal(t, "running", registry.Status.State)
+	assert.True(t, registry.Status.Ready)
+}
+
+func TestStartRegistryAlreadyRunning(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	_, err := f.c.Start(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "", f.docker.lastStartedContainer)
+}
+
+func TestListNotReady(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+	f.c.SetProbeStatus(true)
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+	f.probeErr = fmt.Errorf("connection refused")
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.False(t, registry.Status.Ready)
+	assert.Equal(t, "connection refused", registry.Status.LastProbeError)
+}
+
+func TestListRegistriesPodman(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.host = "unix:///run/user/1000/podman/podman.sock"
+	r := kindRegistry()
+	r.NetworkSettings = &container.NetworkSettingsSummary{
+		Networks: map[string]*network.EndpointSettings{
+			"podman": &network.EndpointSettings{
+				IPAddress: netip.MustParseAddr("10.88.0.2"),
+			},
+			"kind": &network.EndpointSettings{
+				IPAddress: netip.MustParseAddr("10.89.0.3"),
+			},
+		},
+	}
+	f.docker.containers = []container.Summary{r}
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "10.88.0.2", registry.Status.IPAddress)
+	assert.Equal(t, []string{"kind", "podman"}, registry.Status.Networks)
+}
+
+func TestListRegistriesDualStack(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	r := kindRegistry()
+	r.Ports = []container.PortSummary{
+		container.PortSummary{IP: netip.MustParseAddr("::1"), PrivatePort: 5000, PublicPort: 5001, Type: "tcp"},
+		container.PortSummary{IP: netip.MustParseAddr("127.0.0.1"), PrivatePort: 5000, PublicPort: 5001, Type: "tcp"},
+	}
+	r.NetworkSettings.Networks["bridge"].GlobalIPv6Address = netip.MustParseAddr("fd00::2")
+	f.docker.containers = []container.Summary{r}
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", registry.Status.ListenAddress)
+	assert.Equal(t, []string{"127.0.0.1", "::1"}, registry.Status.ListenAddresses)
+	assert.Equal(t, "fd00::2", registry.Status.IPv6Address)
+	assert.Equal(t, 5001, registry.Status.HostPort)
+}
+
+func TestApplyUnmanagedContainer(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	// A registry that someone started by hand, without the role label.
+	unmanaged := kindRegistry()
+	unmanaged.Labels = nil
+	f.docker.containers = []container.Summary{unmanaged}
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"registry kind-registry: a container named kind-registry already exists, but ctlptl didn't create it")
+	}
+	assert.Equal(t, "", f.docker.lastRemovedContainer)
+	assert.Nil(t, f.docker.lastCreateConfig)
+}
+
+func TestListRegistriesIPv6Only(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	r := kindRegistry()
+	r.NetworkSettings = &container.NetworkSettingsSummary{
+		Networks: map[string]*network.EndpointSettings{
+			"bridge": &network.EndpointSettings{
+				GlobalIPv6Address: netip.MustParseAddr("fd00::2"),
+			},
+		},
+	}
+	f.docker.containers = []container.Summary{r}
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "", registry.Status.IPAddress)
+	assert.Equal(t, "fd00::2", registry.Status.IPv6Address)
+}
+
+func TestApplyDualStack(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta:  typeMeta,
+		Name:      "kind-registry",
+		DualStack: true,
+	})
+	require.NoError(t, err)
+
+	// The existing registry is IPv4-only, so it's re-created.
+	hostConfig := f.docker.lastCreateHostConfig
+	require.NotNil(t, hostConfig)
+	bindings := hostConfig.PortBindings[network.MustParsePort("5000/tcp")]
+	require.Len(t, bindings, 2)
+	assert.Equal(t, "127.0.0.1", bindings[0].HostIP.String())
+	assert.Equal(t, "::1", bindings[1].HostIP.String())
+	assert.Equal(t, "5001", bindings[1].HostPort)
+}
+
+func TestApplyIPv6ListenAddress(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta:      typeMeta,
+		Name:          "kind-registry",
+		Port:          5001,
+		ListenAddress: "::1",
+	})
+	require.NoError(t, err)
+
+	hostConfig := f.docker.lastCreateHostConfig
+	require.NotNil(t, hostConfig)
+	bindings := hostConfig.PortBindings[network.MustParsePort("5000/tcp")]
+	require.Len(t, bindings, 1)
+	assert.Equal(t, "::1", bindings[0].HostIP.String())
+}
+
+func TestApplyContainerPort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta:      typeMeta,
+		Name:          "kind-registry",
+		Port:          5001,
+		ContainerPort: 8080,
+	})
+	require.NoError(t, err)
+
+	config := f.docker.lastCreateConfig
+	require.NotNil(t, config)
+	assert.Contains(t, config.ExposedPorts, network.MustParsePort("8080/tcp"))
+	assert.Equal(t, "8080", config.Labels["dev.tilt.ctlptl.registry.containerPort"])
+	assert.ElementsMatch(t, []string{
+		"REGISTRY_HTTP_ADDR=:8080",
+		"REGISTRY_STORAGE_DELETE_ENABLED=true",
+	}, config.Env)
+
+	bindings := f.docker.lastCreateHostConfig.PortBindings[network.MustParsePort("8080/tcp")]
+	require.Len(t, bindings, 1)
+	assert.Equal(t, "5001", bindings[0].HostPort)
+}
+
+func TestListRegistriesContainerPort(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	r := registryBadPorts()
+	r.Labels["dev.tilt.ctlptl.registry.containerPort"] = "5001"
+	f.docker.containers = []container.Summary{r}
+
+	registry, err := f.c.Get(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, 5001, registry.Status.ContainerPort)
+	assert.Equal(t, 5002, registry.Status.HostPort)
+	assert.Empty(t, registry.Status.Warnings)
+}
+
+func TestApplyZot(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Port:     5001,
+		Flavor:   FlavorZot,
+	})
+	require.NoError(t, err)
+
+	config := f.docker.lastCreateConfig
+	require.NotNil(t, config)
+	assert.Equal(t, DefaultZotImageRef, config.Image)
+	assert.Equal(t, "zot", config.Labels["dev.tilt.ctlptl.registry.flavor"])
+
+	// Zot doesn't read distribution's env config.
+	assert.Empty(t, config.Env)
+}
+
+func TestApplyChangeFlavor(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	f.docker.containers = []container.Summary{kindRegistry()}
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Flavor:   FlavorZot,
+	})
+	require.NoError(t, err)
+
+	// The existing registry is distribution, so it's re-created with zot,
+	// on the same host port.
+	config := f.docker.lastCreateConfig
+	require.NotNil(t, config)
+	assert.Equal(t, DefaultZotImageRef, config.Image)
+	bindings := f.docker.lastCreateHostConfig.PortBindings[network.MustParsePort("5000/tcp")]
+	require.Len(t, bindings, 1)
+	assert.Equal(t, "5001", bindings[0].HostPort)
+}
+
+func TestApplyInvalidFlavor(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Flavor:   "harbor",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown registry flavor "harbor"`)
+	}
+}
+
+func TestApplyDualStackInvalid(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	_, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta:      typeMeta,
+		Name:          "kind-registry",
+		ListenAddress: "192.168.1.5",
+		DualStack:     true,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "dualStack requires a loopback or wildcard listen address")
+	}
+}
+
 type fakeCLI struct {
 	client *fakeDocker
 }
@@ -468,10 +999,18 @@ func (c *fakeCLI) AuthInfo(ctx context.Context, ref reference.Reference, cmdName
 }
 
 type fakeDocker struct {
+	host                 string
 	containers           []container.Summary
+	mounts               map[string][]mount.Mount
 	lastRemovedContainer string
+	lastStoppedContainer string
+	lastStartedContainer string
+	lastRemovedVolume    string
 	lastCreateConfig     *container.Config
 	lastCreateHostConfig *container.HostConfig
+
+	// The container that ctlptl runs in, if any.
+	containerID string
 }
 
 type objectNotFoundError struct {
@@ -485,13 +1024,17 @@ func (e objectNotFoundError) Error() string {
 	return fmt.Sprintf("Error: No such %s: %s", e.object, e.id)
 }
 
+func (d *fakeDocker) InsideContainer(ctx context.Context) string {
+	return d.containerID
+}
+
 func (d *fakeDocker) DaemonHost() string {
-	return ""
+	return d.host
 }
 
 func (d *fakeDocker) ContainerInspect(ctx context.Context, containerID string, options client.ContainerInspectOptions) (client.ContainerInspectResult, error) {
 	for _, c := range d.containers {
-		if c.ID == containerID {
+		if c.ID == containerID || slices.Contains(c.Names, "/"+containerID) {
 			return client.ContainerInspectResult{
 				Container: container.InspectResponse{
 					State: &container.State{
@@ -523,6 +1066,9 @@ func (d *fakeDocker) ContainerInspect(ctx context.Context, containerID string, o
 						StopTimeout: (*int)(nil),
 						Shell:       []string(nil),
 					},
+					HostConfig: &container.HostConfig{
+						Mounts: d.mounts[c.ID],
+					},
 					NetworkSettings: &container.NetworkSettings{
 						// MacAddress: "", removed
 					},
@@ -537,18 +1083,31 @@ func (d *fakeDocker) ContainerInspect(ctx context.Context, containerID string, o
 func (d *fakeDocker) ContainerList(ctx context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
 	var result []container.Summary
 	for _, c := range d.containers {
-		// Filter logic removed because we cannot access filters.Args methods easily
-		// and the tests expect specific filtering behavior that the fake might not need
-		// strictly if we return everything (ignoring filtering).
-		// Note: if tests fail due to too many results, we will need to revisit.
+		if !matchesLabelFilters(c.Labels, options.Filters["label"]) {
+			continue
+		}
 		result = append(result, c)
 	}
 	// Cast result to client.ContainerListResult under assumption it is a slice alias
 	return client.ContainerListResult{Items: result}, nil
 }
 
+// Matches label filters of the form key=value.
+func matchesLabelFilters(labels map[string]string, filters map[string]bool) bool {
+	for filter := range filters {
+		k, v, _ := strings.Cut(filter, "=")
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (d *fakeDocker) ContainerRemove(ctx context.Context, id string, options client.ContainerRemoveOptions) (client.ContainerRemoveResult, error) {
 	d.lastRemovedContainer = id
+	d.containers = slices.DeleteFunc(d.containers, func(c container.Summary) bool {
+		return c.ID == id || slices.Contains(c.Names, "/"+id)
+	})
 	return client.ContainerRemoveResult{}, nil
 }
 
@@ -566,13 +1125,43 @@ func (d *fakeDocker) ContainerCreate(ctx context.Context, options client.Contain
 		c.Image = options.Config.Image
 	}
 	d.containers = []container.Summary{c}
+	if options.HostConfig != nil {
+		d.mounts = map[string][]mount.Mount{c.ID: options.HostConfig.Mounts}
+	}
 
 	return client.ContainerCreateResult{}, nil
 }
 func (d *fakeDocker) ContainerStart(ctx context.Context, containerID string,
 	options client.ContainerStartOptions) (client.ContainerStartResult, error) {
+	d.lastStartedContainer = containerID
+	d.setState(containerID, "running")
 	return client.ContainerStartResult{}, nil
 }
+
+func (d *fakeDocker) ContainerCommit(ctx context.Context, containerID string,
+	options client.ContainerCommitOptions) (client.ContainerCommitResult, error) {
+	return client.ContainerCommitResult{}, nil
+}
+
+func (d *fakeDocker) ImageList(ctx context.Context, options client.ImageListOptions) (client.ImageListResult, error) {
+	return client.ImageListResult{}, nil
+}
+
+func (d *fakeDocker) ContainerStop(ctx context.Context, containerID string,
+	options client.ContainerStopOptions) (client.ContainerStopResult, error) {
+	d.lastStoppedContainer = containerID
+	d.setState(containerID, "exited")
+	return client.ContainerStopResult{}, nil
+}
+
+func (d *fakeDocker) setState(containerID string, state container.ContainerState) {
+	for i, c := range d.containers {
+		if c.ID == containerID {
+			d.containers[i].State = state
+		}
+	}
+}
+
 func (d *fakeDocker) ServerVersion(ctx context.Context, options client.ServerVersionOptions) (client.ServerVersionResult, error) {
 	return client.ServerVersionResult{}, nil
 }
@@ -586,10 +1175,20 @@ func (d *fakeDocker) NetworkDisconnect(ctx context.Context, networkID string, op
 	return client.NetworkDisconnectResult{}, nil
 }
 
+func (d *fakeDocker) VolumeRemove(ctx context.Context, volumeID string, options client.VolumeRemoveOptions) (client.VolumeRemoveResult, error) {
+	d.lastRemovedVolume = volumeID
+	return client.VolumeRemoveResult{}, nil
+}
+
 type fixture struct {
 	t      *testing.T
 	c      *Controller
 	docker *fakeDocker
+	runner *cexec.FakeCmdRunner
+	execs  [][]string
+
+	probes   []string
+	probeErr error
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -599,11 +1198,21 @@ func newFixture(t *testing.T) *fixture {
 	controller := NewController(
 		genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr},
 		&fakeCLI{client: d})
-	return &fixture{
+	f := &fixture{
 		t:      t,
 		docker: d,
 		c:      controller,
 	}
+	f.runner = cexec.NewFakeCmdRunner(func(argv []string) string {
+		f.execs = append(f.execs, argv)
+		return ""
+	})
+	controller.runner = f.runner
+	controller.probe = func(ctx context.Context, baseURL string) error {
+		f.probes = append(f.probes, baseURL)
+		return f.probeErr
+	}
+	return f
 }
 
 func (fixture) TearDown() {}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"runtime"
+)
+
+// The image we push to check that a registry is reachable.
+const (
+	TestImageRepo = "ctlptl-verify"
+	TestImageTag  = "latest"
+)
+
+// Pushes a tiny image to the registry from the host, so that
+// we can check that cluster nodes can pull from it.
+//
+// Returns the image reference, without the registry host.
+func (c *Controller) PushTestImage(ctx context.Context, name string) (string, error) {
+	registry, err := c.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if registry.Status.State != containerStateRunning {
+		return "", fmt.Errorf("registry %s is not running (state: %s)", name, registry.Status.State)
+	}
+
+	baseURL, err := hostURL(registry, c.dockerCLI.Client().DaemonHost())
+	if err != nil {
+		return "", err
+	}
+	client := newAPIClient(baseURL)
+
+	m, blobs, err := testImage()
+	if err != nil {
+		return "", err
+	}
+	for _, blob := range blobs {
+		err := client.uploadBlob(ctx, TestImageRepo, blob)
+		if err != nil {
+			return "", fmt.Errorf("pushing test image to registry %s: %v", name, err)
+		}
+	}
+
+	err = client.putManifest(ctx, TestImageRepo, TestImageTag, m)
+	if err != nil {
+		return "", fmt.Errorf("pushing test image to registry %s: %v", name, err)
+	}
+	return fmt.Sprintf("%s:%s", TestImageRepo, TestImageTag), nil
+}
+
+// Builds an image with a single empty layer.
+//
+// Returns the manifest, and the config and layer blobs.
+func testImage() (manifest, []blob, error) {
+	tarBuf := bytes.NewBuffer(nil)
+	err := tar.NewWriter(tarBuf).Close()
+	if err != nil {
+		return manifest{}, nil, err
+	}
+
+	layerBuf := bytes.NewBuffer(nil)
+	gz := gzip.NewWriter(layerBuf)
+	_, err = gz.Write(tarBuf.Bytes())
+	if err != nil {
+		return manifest{}, nil, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return manifest{}, nil, err
+	}
+	layer := layerBuf.Bytes()
+
+	// Nodes run Linux on the same architecture as the host.
+	config, err := json.Marshal(map[string]interface{}{
+		"architecture": runtime.GOARCH,
+		"os":           "linux",
+		"config":       map[string]interface{}{},
+		"rootfs": map[string]interface{}{
+			"type":     "layers",
+			"diff_ids": []string{fmt.Sprintf("sha256:%x", sha256.Sum256(tarBuf.Bytes()))},
+		},
+	})
+	if err != nil {
+		return manifest{}, nil, err
+	}
+
+	configBlob := newBlob("application/vnd.oci.image.config.v1+json", config)
+	layerBlob := newBlob("application/vnd.oci.image.layer.v1.tar+gzip", layer)
+	m := manifest{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.manifest.v1+json",
+		Config:        &configBlob.desc,
+		Layers:        []descriptor{layerBlob.desc},
+	}
+	return m, []blob{configBlob, layerBlob}, nil
+}
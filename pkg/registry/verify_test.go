@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushTestImage(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	s := newFakeRegistryServer(t)
+	f.useServer(s)
+
+	ref, err := f.c.PushTestImage(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, "ctlptl-verify:latest", ref)
+
+	data, ok := s.manifests["ctlptl-verify:latest"]
+	require.True(t, ok)
+	m := manifest{}
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.NotNil(t, m.Config)
+	require.Len(t, m.Layers, 1)
+	assert.Equal(t, 2, m.SchemaVersion)
+	assert.Len(t, s.blobs, 2)
+	assert.Contains(t, s.blobs, m.Config.Digest)
+	assert.Contains(t, s.blobs, m.Layers[0].Digest)
+
+	// Pushing again skips the blobs the registry already has.
+	s.blobs = map[string][]byte{m.Config.Digest: nil, m.Layers[0].Digest: nil}
+	_, err = f.c.PushTestImage(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Nil(t, s.blobs[m.Config.Digest])
+}
+
+func TestPushTestImageNotRunning(t *testing.T) {
+	f := newFixture(t)
+	defer f.TearDown()
+
+	r := kindRegistry()
+	r.State = "exited"
+	f.docker.containers = []container.Summary{r}
+
+	_, err := f.c.PushTestImage(context.Background(), "kind-registry")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not running")
+	}
+}
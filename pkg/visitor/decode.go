@@ -1,8 +1,12 @@
 package visitor
 
 import (
+	"io"
+
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/tilt-dev/ctlptl/pkg/encoding"
 )
@@ -34,3 +38,44 @@ func Decode(v Interface) ([]runtime.Object, error) {
 	}
 	return result, nil
 }
+
+// Decodes arbitrary Kubernetes objects, like the manifests
+// that we apply to a cluster after creating it.
+func DecodeUnstructured(v Interface) ([]*unstructured.Unstructured, error) {
+	r, err := v.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	result := []*unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "visiting %s", v.Name())
+		}
+		if len(obj.Object) == 0 {
+			// Skip empty documents.
+			continue
+		}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				result = append(result, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "visiting %s", v.Name())
+			}
+			continue
+		}
+		result = append(result, obj)
+	}
+	return result, nil
+}
@@ -4,6 +4,9 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -25,9 +28,42 @@ func FromStrings(filenames []string, stdin io.Reader) ([]Interface, error) {
 			result = append(result, URL(http.DefaultClient, f))
 
 		default:
+			info, err := os.Stat(f)
+			if err == nil && info.IsDir() {
+				files, err := dirFiles(f)
+				if err != nil {
+					return nil, err
+				}
+				for _, file := range files {
+					result = append(result, File(file))
+				}
+				continue
+			}
 			result = append(result, File(f))
 
 		}
 	}
 	return result, nil
 }
+
+// The YAML and JSON files in a directory, in lexical order.
+//
+// Like kubectl, doesn't recurse into subdirectories.
+func dirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading directory %s", dir)
+	}
+	files := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch filepath.Ext(e.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}